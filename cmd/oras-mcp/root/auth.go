@@ -0,0 +1,156 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// authTokenEnv is the environment variable holding the bearer token required
+// by the HTTP transports, if no token file is specified.
+const authTokenEnv = "ORAS_MCP_AUTH_TOKEN"
+
+// authenticator rejects HTTP requests that present neither the configured
+// bearer token nor a verified client certificate.
+type authenticator struct {
+	// token is the static bearer token. Bearer authentication is disabled if
+	// empty.
+	token []byte
+	// clientCert indicates whether verified client certificates are accepted.
+	clientCert bool
+}
+
+// newAuthenticator creates an authenticator from the serve options. It returns
+// nil if no authentication is configured.
+func newAuthenticator(opts *serveOptions) (*authenticator, error) {
+	token, err := loadAuthToken(opts.authTokenFile)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" && opts.tlsClientCAFile == "" {
+		return nil, nil
+	}
+	return &authenticator{
+		token:      []byte(token),
+		clientCert: opts.tlsClientCAFile != "",
+	}, nil
+}
+
+// wrap returns a handler authenticating requests before passing them to next.
+func (a *authenticator) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.authenticated(r) {
+			if len(a.token) > 0 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated reports whether the request is authenticated.
+func (a *authenticator) authenticated(r *http.Request) bool {
+	if a.clientCert && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return true
+	}
+	if len(a.token) == 0 {
+		return false
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), a.token) == 1
+}
+
+// loadAuthToken reads the bearer token from the token file, or from the
+// environment if no file is specified.
+func loadAuthToken(path string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(os.Getenv(authTokenEnv)), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read auth token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("auth token file %q is empty", path)
+	}
+	return token, nil
+}
+
+// loadTLSConfig assembles the TLS configuration of the HTTP transports. It
+// returns nil if TLS is not configured.
+func loadTLSConfig(opts *serveOptions) (*tls.Config, error) {
+	if (opts.tlsCertFile == "") != (opts.tlsKeyFile == "") {
+		return nil, errors.New("both --tls-cert and --tls-key are required to serve TLS")
+	}
+	if opts.tlsCertFile == "" {
+		if opts.tlsClientCAFile != "" {
+			return nil, errors.New("--tls-client-ca requires --tls-cert and --tls-key")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(opts.tlsCertFile, opts.tlsKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if opts.tlsClientCAFile != "" {
+		pem, err := os.ReadFile(opts.tlsClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %q", opts.tlsClientCAFile)
+		}
+		config.ClientCAs = pool
+		// client certificates are verified if presented. Requests without one
+		// are left to the authenticator, which may accept a bearer token.
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
+
+// isLoopback reports whether the listen address only accepts local
+// connections.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
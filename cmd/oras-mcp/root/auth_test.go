@@ -0,0 +1,407 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCertificate is a certificate with its private key for testing.
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCertificate issues a certificate signed by parent, or a self-signed
+// CA certificate if parent is nil.
+func newTestCertificate(t *testing.T, parent *testCertificate, usage x509.ExtKeyUsage) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "oras-mcp-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return &testCertificate{cert: cert, key: key, der: der}
+}
+
+// writeFiles writes the certificate and its key in PEM format to dir.
+func (c *testCertificate) writeFiles(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+// tlsCertificate returns the certificate for use in a TLS configuration.
+func (c *testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestAuthenticator_BearerToken(t *testing.T) {
+	authn := &authenticator{token: []byte("secret")}
+	handler := authn.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{
+			name:          "valid token",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:          "case-insensitive scheme",
+			authorization: "bearer secret",
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "missing token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong token",
+			authorization: "Bearer guess",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong scheme",
+			authorization: "Basic secret",
+			wantStatus:    http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Fatalf("expected WWW-Authenticate challenge, got %q", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthenticator_ClientCertificate(t *testing.T) {
+	tempDir := t.TempDir()
+	ca := newTestCertificate(t, nil, x509.ExtKeyUsageAny)
+	caFile, _ := ca.writeFiles(t, tempDir, "ca")
+	serverCert := newTestCertificate(t, ca, x509.ExtKeyUsageServerAuth)
+	certFile, keyFile := serverCert.writeFiles(t, tempDir, "server")
+	clientCert := newTestCertificate(t, ca, x509.ExtKeyUsageClientAuth)
+	untrustedCert := newTestCertificate(t, newTestCertificate(t, nil, x509.ExtKeyUsageAny), x509.ExtKeyUsageClientAuth)
+
+	opts := &serveOptions{
+		tlsCertFile:     certFile,
+		tlsKeyFile:      keyFile,
+		tlsClientCAFile: caFile,
+	}
+	t.Setenv(authTokenEnv, "")
+	tlsConfig, err := loadTLSConfig(opts)
+	if err != nil {
+		t.Fatalf("loadTLSConfig() error = %v", err)
+	}
+	authn, err := newAuthenticator(opts)
+	if err != nil {
+		t.Fatalf("newAuthenticator() error = %v", err)
+	}
+	ts := httptest.NewUnstartedServer(authn.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	tests := []struct {
+		name         string
+		certificates []tls.Certificate
+		wantStatus   int
+		wantErr      bool
+	}{
+		{
+			name:         "trusted client certificate",
+			certificates: []tls.Certificate{clientCert.tlsCertificate()},
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:       "no client certificate",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "untrusted client certificate",
+			certificates: []tls.Certificate{untrustedCert.tlsCertificate()},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:      roots,
+						Certificates: tt.certificates,
+					},
+				},
+			}
+			resp, err := client.Get(ts.URL)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("expected TLS handshake error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	tempDir := t.TempDir()
+	tokenFile := filepath.Join(tempDir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	emptyTokenFile := filepath.Join(tempDir, "empty")
+	if err := os.WriteFile(emptyTokenFile, []byte("  \n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      *serveOptions
+		env       string
+		wantNil   bool
+		wantToken string
+		wantErr   bool
+	}{
+		{
+			name:    "no authentication",
+			opts:    &serveOptions{},
+			wantNil: true,
+		},
+		{
+			name:      "token from environment",
+			opts:      &serveOptions{},
+			env:       "from-env",
+			wantToken: "from-env",
+		},
+		{
+			name:      "token file takes precedence",
+			opts:      &serveOptions{authTokenFile: tokenFile},
+			env:       "from-env",
+			wantToken: "from-file",
+		},
+		{
+			name:    "empty token file",
+			opts:    &serveOptions{authTokenFile: emptyTokenFile},
+			wantErr: true,
+		},
+		{
+			name:    "missing token file",
+			opts:    &serveOptions{authTokenFile: filepath.Join(tempDir, "missing")},
+			wantErr: true,
+		},
+		{
+			name: "client certificates only",
+			opts: &serveOptions{tlsClientCAFile: "ca.crt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(authTokenEnv, tt.env)
+			authn, err := newAuthenticator(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newAuthenticator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (authn == nil) != tt.wantNil {
+				t.Fatalf("newAuthenticator() = %v, wantNil %v", authn, tt.wantNil)
+			}
+			if authn != nil && string(authn.token) != tt.wantToken {
+				t.Fatalf("unexpected token: got %q, want %q", authn.token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	ca := newTestCertificate(t, nil, x509.ExtKeyUsageAny)
+	caFile, _ := ca.writeFiles(t, tempDir, "ca")
+	certFile, keyFile := newTestCertificate(t, ca, x509.ExtKeyUsageServerAuth).writeFiles(t, tempDir, "server")
+	invalidFile := filepath.Join(tempDir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		opts           *serveOptions
+		wantNil        bool
+		wantClientAuth tls.ClientAuthType
+		wantErr        bool
+	}{
+		{
+			name:    "no TLS",
+			opts:    &serveOptions{},
+			wantNil: true,
+		},
+		{
+			name:           "server certificate",
+			opts:           &serveOptions{tlsCertFile: certFile, tlsKeyFile: keyFile},
+			wantClientAuth: tls.NoClientCert,
+		},
+		{
+			name:           "client CA",
+			opts:           &serveOptions{tlsCertFile: certFile, tlsKeyFile: keyFile, tlsClientCAFile: caFile},
+			wantClientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:    "missing key",
+			opts:    &serveOptions{tlsCertFile: certFile},
+			wantErr: true,
+		},
+		{
+			name:    "client CA without server certificate",
+			opts:    &serveOptions{tlsClientCAFile: caFile},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key pair",
+			opts:    &serveOptions{tlsCertFile: invalidFile, tlsKeyFile: keyFile},
+			wantErr: true,
+		},
+		{
+			name:    "invalid client CA",
+			opts:    &serveOptions{tlsCertFile: certFile, tlsKeyFile: keyFile, tlsClientCAFile: invalidFile},
+			wantErr: true,
+		},
+		{
+			name:    "missing client CA",
+			opts:    &serveOptions{tlsCertFile: certFile, tlsKeyFile: keyFile, tlsClientCAFile: filepath.Join(tempDir, "missing")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := loadTLSConfig(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (config == nil) != tt.wantNil {
+				t.Fatalf("loadTLSConfig() = %v, wantNil %v", config, tt.wantNil)
+			}
+			if config != nil && config.ClientAuth != tt.wantClientAuth {
+				t.Fatalf("unexpected client auth: got %v, want %v", config.ClientAuth, tt.wantClientAuth)
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{address: "localhost:8080", want: true},
+		{address: "127.0.0.1:8080", want: true},
+		{address: "[::1]:8080", want: true},
+		{address: ":8080", want: false},
+		{address: "0.0.0.0:8080", want: false},
+		{address: "example.com:8080", want: false},
+		{address: "invalid", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := isLoopback(tt.address); got != tt.want {
+				t.Errorf("isLoopback(%q) = %v, want %v", tt.address, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServeHTTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serveHTTP(ctx, listener, newHTTPHandler(staticServer(newServer())))
+	}()
+
+	// connect two independent sessions to the same server
+	endpoint := "http://" + listener.Addr().String()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	var sessions []*mcp.ClientSession
+	for range 2 {
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: endpoint}, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if sessions[0].ID() == "" || sessions[0].ID() == sessions[1].ID() {
+		t.Fatalf("expected distinct session IDs, got %q and %q", sessions[0].ID(), sessions[1].ID())
+	}
+
+	for _, session := range sessions {
+		result, err := session.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to list tools: %v", err)
+		}
+		found := false
+		for _, tool := range result.Tools {
+			if tool.Name == "parse_reference" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected parse_reference tool to be served")
+		}
+	}
+
+	result, err := sessions[0].CallTool(ctx, &mcp.CallToolParams{
+		Name:      "parse_reference",
+		Arguments: map[string]any{"reference": "localhost:5000/hello:v1"},
+	})
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %v", result.Content)
+	}
+	for _, session := range sessions {
+		if err := session.Close(); err != nil {
+			t.Fatalf("failed to close session: %v", err)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("expected graceful shutdown, got error: %v", err)
+		}
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatalf("serveHTTP did not return within timeout")
+	}
+}
+
+func TestServeHTTPShutdownWithOpenStream(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serveHTTP(ctx, listener, newHTTPHandler(staticServer(newServer())))
+	}()
+
+	// keep the session open so that the hanging GET stream stays active
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   "http://" + listener.Addr().String(),
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	start := time.Now()
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("expected graceful shutdown, got error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= shutdownTimeout {
+			t.Fatalf("shutdown waited for the shutdown timeout: %v", elapsed)
+		}
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatalf("serveHTTP did not return within timeout")
+	}
+}
+
+func TestServeHTTPListenerError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	if err := listener.Close(); err != nil {
+		t.Fatalf("failed to close listener: %v", err)
+	}
+
+	if err := serveHTTP(context.Background(), listener, newHTTPHandler(staticServer(newServer()))); err == nil {
+		t.Fatalf("expected error when serving on a closed listener")
+	}
+}
+
+// staticServer returns a function serving all sessions with the same server.
+func staticServer(server *mcp.Server) func(*http.Request) *mcp.Server {
+	return func(*http.Request) *mcp.Server {
+		return server
+	}
+}
@@ -16,13 +16,43 @@ limitations under the License.
 package root
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/cache"
+	"github.com/oras-project/oras-mcp/internal/config"
+	"github.com/oras-project/oras-mcp/internal/remote"
 	"github.com/oras-project/oras-mcp/internal/tool"
 	"github.com/oras-project/oras-mcp/internal/version"
 	"github.com/spf13/cobra"
 )
 
+// Supported transports of the serve command.
+const (
+	transportStdio = "stdio"
+	transportHTTP  = "http"
+	transportSSE   = "sse"
+)
+
+type serveOptions struct {
+	configFile      string
+	transport       string
+	listen          string
+	authTokenFile   string
+	tlsCertFile     string
+	tlsKeyFile      string
+	tlsClientCAFile string
+	credentials     string
+	cacheDir        string
+}
+
 func serveCmd() *cobra.Command {
+	var opts serveOptions
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start the ORAS MCP server",
@@ -30,17 +60,119 @@ func serveCmd() *cobra.Command {
 
 Example - start the server in the stdio mode:
   oras serve
+
+Example - start the server with a configuration file:
+  oras-mcp serve --config config.yaml
+
+Example - start the server caching the content fetched by digest on disk:
+  oras-mcp serve --cache-dir ~/.cache/oras-mcp
+
+Example - start the server with the streamable HTTP transport on port 8080:
+  oras-mcp serve --transport http --listen localhost:8080
+
+Example - start the server with the legacy HTTP+SSE transport for older clients:
+  oras-mcp serve --transport sse --listen localhost:8080
+
+Example - serve HTTPS on all interfaces, requiring a bearer token:
+  oras-mcp serve --transport http --listen :8443 --tls-cert server.crt --tls-key server.key --auth-token-file token.txt
+
+Example - serve HTTPS on all interfaces, requiring client certificates:
+  oras-mcp serve --transport http --listen :8443 --tls-cert server.crt --tls-key server.key --tls-client-ca ca.crt
+
+Example - access registries with the credentials forwarded by each client in the X-Registry-Auth header:
+  oras-mcp serve --transport http --listen :8443 --tls-cert server.crt --tls-key server.key --auth-token-file token.txt --registry-credentials header
 `,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runServe(cmd)
+			return runServe(cmd, &opts)
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.configFile, "config", "", "path of the YAML or JSON configuration file, read from $"+config.EnvConfig+" if not set")
+	cmd.Flags().StringVar(&opts.transport, "transport", transportStdio, `transport of the MCP server, options: "stdio", "http", "sse"`)
+	cmd.Flags().StringVar(&opts.listen, "listen", "localhost:8080", "address to listen on for the http and sse transports")
+	cmd.Flags().StringVar(&opts.authTokenFile, "auth-token-file", "", "path of the file containing the bearer token required by the http and sse transports, read from $"+authTokenEnv+" if not set")
+	cmd.Flags().StringVar(&opts.tlsCertFile, "tls-cert", "", "path of the TLS certificate to serve the http and sse transports with")
+	cmd.Flags().StringVar(&opts.tlsKeyFile, "tls-key", "", "path of the TLS private key to serve the http and sse transports with")
+	cmd.Flags().StringVar(&opts.tlsClientCAFile, "tls-client-ca", "", "path of the CA bundle to verify client certificates against, accepting them as an alternative to the bearer token")
+	cmd.Flags().StringVar(&opts.credentials, "registry-credentials", credentialsDocker, `source of registry credentials for each session of the http and sse transports, options: "docker" (Docker credential store of the server), "header" (forwarded by the client in the `+remote.RegistryAuthHeader+` header)`)
+	cmd.Flags().StringVar(&opts.cacheDir, "cache-dir", "", "directory of the persistent content cache, overriding the cache.dir configuration")
 	return cmd
 }
 
-func runServe(cmd *cobra.Command) error {
+func runServe(cmd *cobra.Command, opts *serveOptions) error {
+	ctx := cmd.Context()
+	configFile := opts.configFile
+	if configFile == "" {
+		configFile = os.Getenv(config.EnvConfig)
+	}
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return err
+	}
+	if opts.cacheDir != "" {
+		cfg.Cache.Dir = opts.cacheDir
+	}
+	remote.Configure(cfg.Registry)
+	tool.Configure(cfg.Tool)
+	if cfg.Cache.Dir != "" {
+		c, err := cache.New(ctx, cfg.Cache.Dir, cfg.Cache.MaxSize)
+		if err != nil {
+			return err
+		}
+		remote.SetCache(c)
+	}
+
+	switch opts.transport {
+	case transportStdio:
+		return newServer().Run(ctx, &mcp.StdioTransport{})
+	case transportHTTP, transportSSE:
+		return runServeHTTP(ctx, opts)
+	default:
+		return fmt.Errorf("unsupported transport %q", opts.transport)
+	}
+}
+
+// runServeHTTP serves the MCP server over the HTTP based transports.
+func runServeHTTP(ctx context.Context, opts *serveOptions) error {
+	tlsConfig, err := loadTLSConfig(opts)
+	if err != nil {
+		return err
+	}
+	authn, err := newAuthenticator(opts)
+	if err != nil {
+		return err
+	}
+	getServer, err := sessionServerFunc(opts.credentials)
+	if err != nil {
+		return err
+	}
+	if authn == nil && !isLoopback(opts.listen) {
+		// anyone reaching the server could act with the registry credentials
+		// of the server.
+		return fmt.Errorf("refusing to serve on non-loopback address %q without authentication: set --auth-token-file, $%s, or --tls-client-ca", opts.listen, authTokenEnv)
+	}
+
+	handler := newHTTPHandler(getServer)
+	if opts.transport == transportSSE {
+		handler = newSSEHandler(getServer)
+	}
+	if authn != nil {
+		handler = authn.wrap(handler)
+	}
+
+	listener, err := net.Listen("tcp", opts.listen)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return serveHTTP(ctx, listener, handler)
+}
+
+// newServer creates an MCP server with all tools registered.
+func newServer() *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "oras-mcp",
 		Title:   "ORAS",
@@ -52,9 +184,32 @@ func runServe(cmd *cobra.Command) error {
 	mcp.AddTool(server, tool.MetadataListRepositories, tool.ListRepositories)
 	mcp.AddTool(server, tool.MetadataListTags, tool.ListTags)
 	mcp.AddTool(server, tool.MetadataListReferrers, tool.ListReferrers)
+	mcp.AddTool(server, tool.MetadataResolveReference, tool.ResolveReference)
 	mcp.AddTool(server, tool.MetadataFetchManifest, tool.FetchManifest)
+	mcp.AddTool(server, tool.MetadataListPlatforms, tool.ListPlatforms)
+	mcp.AddTool(server, tool.MetadataFetchImageConfig, tool.FetchImageConfig)
+	mcp.AddTool(server, tool.MetadataInspectLayers, tool.InspectLayers)
+	mcp.AddTool(server, tool.MetadataListLayerFiles, tool.ListLayerFiles)
+	mcp.AddTool(server, tool.MetadataReadImageFile, tool.ReadImageFile)
+	mcp.AddTool(server, tool.MetadataDiffImageFilesystems, tool.DiffImageFilesystems)
 	mcp.AddTool(server, tool.MetadataFetchBlob, tool.FetchBlob)
 	mcp.AddTool(server, tool.MetadataParseReference, tool.ParseReference)
 
-	return server.Run(cmd.Context(), &mcp.StdioTransport{})
+	return server
+}
+
+// newHTTPHandler creates an HTTP handler serving the MCP streamable HTTP
+// transport. Sessions are tracked by the handler via the Mcp-Session-Id header,
+// and getServer is called to create the server of each new session.
+func newHTTPHandler(getServer func(*http.Request) *mcp.Server) http.Handler {
+	return mcp.NewStreamableHTTPHandler(getServer, nil)
+}
+
+// newSSEHandler creates an HTTP handler serving the legacy MCP HTTP+SSE
+// transport for clients not supporting the streamable HTTP transport yet.
+// Each GET request opens a new session served by the server from getServer,
+// which receives messages posted to the session endpoint advertised in its
+// event stream.
+func newSSEHandler(getServer func(*http.Request) *mcp.Server) http.Handler {
+	return mcp.NewSSEHandler(getServer, nil)
 }
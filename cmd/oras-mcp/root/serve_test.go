@@ -17,12 +17,20 @@ package root
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/config"
 	"github.com/oras-project/oras-mcp/internal/tool"
 	"github.com/spf13/cobra"
 )
@@ -115,7 +123,7 @@ func TestRunServeReturnsErrorOnCanceledContext(t *testing.T) {
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runServe(cmd)
+		errCh <- runServe(cmd, &serveOptions{transport: transportStdio})
 	}()
 
 	select {
@@ -127,3 +135,224 @@ func TestRunServeReturnsErrorOnCanceledContext(t *testing.T) {
 		t.Fatalf("runServe did not return within timeout")
 	}
 }
+
+func TestServeCommandFlags(t *testing.T) {
+	t.Parallel()
+
+	cmd := serveCmd()
+
+	transport := cmd.Flags().Lookup("transport")
+	if transport == nil {
+		t.Fatalf("expected transport flag to be defined")
+	}
+	if transport.DefValue != transportStdio {
+		t.Fatalf("unexpected default transport: %q", transport.DefValue)
+	}
+
+	listen := cmd.Flags().Lookup("listen")
+	if listen == nil {
+		t.Fatalf("expected listen flag to be defined")
+	}
+	if listen.DefValue != "localhost:8080" {
+		t.Fatalf("unexpected default listen address: %q", listen.DefValue)
+	}
+
+	if cmd.Flags().Lookup("cache-dir") == nil {
+		t.Fatalf("expected cache-dir flag to be defined")
+	}
+}
+
+func TestRunServeUnsupportedTransport(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	err := runServe(cmd, &serveOptions{transport: "carrier-pigeon"})
+	if err == nil {
+		t.Fatalf("expected error for unsupported transport")
+	}
+	if !strings.Contains(err.Error(), "unsupported transport") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunServeHTTPInvalidListenAddress(t *testing.T) {
+	t.Setenv(authTokenEnv, "secret")
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	err := runServe(cmd, &serveOptions{transport: transportHTTP, listen: "invalid:address:format", credentials: credentialsDocker})
+	if err == nil {
+		t.Fatalf("expected error for invalid listen address")
+	}
+}
+
+func TestNewSSEHandler(t *testing.T) {
+	ts := httptest.NewServer(newSSEHandler(staticServer(newServer())))
+	defer ts.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, &mcp.SSEClientTransport{
+		Endpoint:   ts.URL,
+		HTTPClient: ts.Client(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	if len(tools.Tools) != 14 {
+		t.Fatalf("expected 14 tools, got %d", len(tools.Tools))
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "parse_reference",
+		Arguments: map[string]any{"reference": "localhost:5000/hello:v1"},
+	})
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %v", result.Content)
+	}
+	got, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to marshal structured content: %v", err)
+	}
+	want := `{"registry":"localhost:5000","repository":"hello","tag":"v1"}`
+	if string(got) != want {
+		t.Fatalf("unexpected tool output: got %s, want %s", got, want)
+	}
+}
+
+func TestNewSSEHandlerRejectsUnknownSession(t *testing.T) {
+	ts := httptest.NewServer(newSSEHandler(staticServer(newServer())))
+	defer ts.Close()
+
+	resp, err := ts.Client().Post(ts.URL+"?sessionid=unknown", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("failed to post message: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected status code: got %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestRunServeHTTPRequiresAuthentication(t *testing.T) {
+	t.Setenv(authTokenEnv, "")
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	err := runServe(cmd, &serveOptions{transport: transportHTTP, listen: ":0", credentials: credentialsDocker})
+	if err == nil {
+		t.Fatalf("expected error when serving on non-loopback address without authentication")
+	}
+	if !strings.Contains(err.Error(), "without authentication") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunServeHTTPWithAuthentication(t *testing.T) {
+	t.Setenv(authTokenEnv, "secret")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatalf("failed to close listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runServe(cmd, &serveOptions{transport: transportHTTP, listen: address, credentials: credentialsDocker})
+	}()
+
+	endpoint := "http://" + address
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	var session *mcp.ClientSession
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		session, err = client.Connect(ctx, &mcp.StreamableClientTransport{
+			Endpoint:   endpoint,
+			HTTPClient: &http.Client{Transport: &headerTransport{key: "Authorization", value: "Bearer secret"}},
+			MaxRetries: -1,
+		}, nil)
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to connect with token: %v", err)
+	}
+	if _, err := session.ListTools(ctx, nil); err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	session.Close()
+
+	if _, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   endpoint,
+		MaxRetries: -1,
+	}, nil); err == nil {
+		t.Fatalf("expected unauthenticated session to be rejected")
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("expected graceful shutdown, got error: %v", err)
+		}
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatalf("runServe did not return within timeout")
+	}
+}
+
+func TestRunServeInvalidConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("tool:\n  maxBlobSize: -1\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	err := runServe(cmd, &serveOptions{configFile: configFile, transport: transportStdio})
+	if err == nil {
+		t.Fatalf("expected error for invalid configuration")
+	}
+	if !strings.Contains(err.Error(), "invalid configuration") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Setenv(config.EnvConfig, configFile)
+	if err := runServe(cmd, &serveOptions{transport: transportStdio}); err == nil {
+		t.Fatalf("expected error for invalid configuration from environment")
+	}
+}
+
+func TestRunServeInvalidCacheDir(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(cacheDir, nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	err := runServe(cmd, &serveOptions{transport: transportStdio, cacheDir: cacheDir})
+	if err == nil {
+		t.Fatalf("expected error for invalid cache directory")
+	}
+	if !strings.Contains(err.Error(), "failed to open cache") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
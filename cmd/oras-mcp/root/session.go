@@ -0,0 +1,68 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// Sources of registry credentials for the HTTP transports.
+const (
+	credentialsDocker = "docker"
+	credentialsHeader = "header"
+)
+
+// sessionServerFunc returns a function creating an MCP server for each new
+// session of the HTTP transports. Registry requests of a session are
+// authenticated by an auth client of its own, so that tokens obtained for one
+// session never serve another.
+func sessionServerFunc(source string) (func(*http.Request) *mcp.Server, error) {
+	switch source {
+	case credentialsDocker:
+		credential := remote.DefaultClient.Credential
+		return func(*http.Request) *mcp.Server {
+			return newSessionServer(remote.NewClient(credential))
+		}, nil
+	case credentialsHeader:
+		return func(r *http.Request) *mcp.Server {
+			credential, err := remote.CredentialFromHeader(r.Header)
+			if err != nil {
+				// the session is rejected by the MCP SDK
+				return nil
+			}
+			return newSessionServer(remote.NewClient(credential))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported registry credentials source %q", source)
+	}
+}
+
+// newSessionServer creates an MCP server accessing registries with client.
+func newSessionServer(client *auth.Client) *mcp.Server {
+	server := newServer()
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(remote.WithClient(ctx, client), method, req)
+		}
+	})
+	return server
+}
@@ -0,0 +1,183 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+func TestSessionServerFunc_Unsupported(t *testing.T) {
+	if _, err := sessionServerFunc("keychain"); err == nil {
+		t.Fatalf("expected error for unsupported credentials source")
+	}
+}
+
+func TestSessionServerFunc_Docker(t *testing.T) {
+	getServer, err := sessionServerFunc(credentialsDocker)
+	if err != nil {
+		t.Fatalf("sessionServerFunc() error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	if getServer(req) == getServer(req) {
+		t.Fatalf("expected a new server for each session")
+	}
+}
+
+func TestSessionServerFunc_InvalidHeader(t *testing.T) {
+	getServer, err := sessionServerFunc(credentialsHeader)
+	if err != nil {
+		t.Fatalf("sessionServerFunc() error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(remote.RegistryAuthHeader, "invalid")
+	if server := getServer(req); server != nil {
+		t.Fatalf("expected no server for invalid registry credentials")
+	}
+}
+
+func TestNewSessionServer(t *testing.T) {
+	client := remote.NewClient(nil)
+	server := newSessionServer(client)
+
+	// probe the auth client seen by tool handlers
+	type probeOutput struct {
+		SameClient bool `json:"sameClient"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "probe"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, probeOutput, error) {
+		return nil, probeOutput{SameClient: remote.ClientFromContext(ctx) == client}, nil
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "probe"})
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+	got, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to marshal structured content: %v", err)
+	}
+	if string(got) != `{"sameClient":true}` {
+		t.Fatalf("expected tool handler to use the session client, got %s", got)
+	}
+}
+
+func TestSessionServerFunc_HeaderCredentials(t *testing.T) {
+	// the registry lists tags named after the authenticated user
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, _, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name": "test-repo",
+			"tags": []string{username},
+		})
+	}))
+	defer registry.Close()
+	u, err := url.Parse(registry.URL)
+	if err != nil {
+		t.Fatalf("failed to parse registry URL: %v", err)
+	}
+	_, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatalf("failed to parse registry host: %v", err)
+	}
+	registryName := "localhost:" + port
+
+	getServer, err := sessionServerFunc(credentialsHeader)
+	if err != nil {
+		t.Fatalf("sessionServerFunc() error = %v", err)
+	}
+	ts := httptest.NewServer(newHTTPHandler(getServer))
+	defer ts.Close()
+
+	ctx := context.Background()
+	for _, username := range []string{"alice", "bob"} {
+		t.Run(username, func(t *testing.T) {
+			credential := base64.StdEncoding.EncodeToString([]byte(username + ":secret"))
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+			session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+				Endpoint: ts.URL,
+				HTTPClient: &http.Client{Transport: &headerTransport{
+					key:   remote.RegistryAuthHeader,
+					value: registryName + "=" + credential,
+				}},
+			}, nil)
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer session.Close()
+
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{
+				Name: "list_tags",
+				Arguments: map[string]any{
+					"registry":   registryName,
+					"repository": "test-repo",
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to call tool: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected tool error: %v", result.Content)
+			}
+			got, err := json.Marshal(result.StructuredContent)
+			if err != nil {
+				t.Fatalf("failed to marshal structured content: %v", err)
+			}
+			if want := `{"tags":["` + username + `"]}`; string(got) != want {
+				t.Fatalf("unexpected tool output: got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// headerTransport sets a header on each request.
+type headerTransport struct {
+	key   string
+	value string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.key, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -4,10 +4,13 @@ go 1.25.0
 
 require (
 	github.com/google/jsonschema-go v0.3.0
+	github.com/klauspost/compress v1.18.0
 	github.com/modelcontextprotocol/go-sdk v0.8.0
 	github.com/opencontainers/go-digest v1.0.0
 	github.com/opencontainers/image-spec v1.1.1
 	github.com/spf13/cobra v1.10.1
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/mod v0.29.0
 	oras.land/oras-go/v2 v2.6.0
 )
 
@@ -0,0 +1,310 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache implements the persistent on-disk content cache of oras-mcp.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// errIncomplete is the error aborting the caching of content not read to the
+// end.
+var errIncomplete = errors.New("content not read to the end")
+
+// Cache is a content-addressable cache backed by an OCI image layout on disk.
+// Once the cached content exceeds the maximum size, the least recently used
+// content is evicted. It is safe for concurrent use.
+type Cache struct {
+	root    string
+	maxSize int64
+	store   *oci.Store
+
+	mu      sync.Mutex
+	size    int64
+	entries map[digest.Digest]*entry
+}
+
+// entry is a cached content.
+type entry struct {
+	desc     ocispec.Descriptor
+	lastUsed time.Time
+}
+
+// New opens the cache at the root directory, creating it if not exist, and
+// bounds the size of the cached content by maxSize bytes. The last use of
+// cached content is tracked by the modification time of its file so that the
+// eviction order survives restarts.
+func New(ctx context.Context, root string, maxSize int64) (*Cache, error) {
+	store, err := oci.NewWithContext(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache %s: %w", root, err)
+	}
+	// the dangling content is evicted in the LRU order instead
+	store.AutoGC = false
+
+	c := &Cache{
+		root:    root,
+		maxSize: maxSize,
+		store:   store,
+		entries: make(map[digest.Digest]*entry),
+	}
+	if err := c.load(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load cache %s: %w", root, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.evict(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes the content already in the cache.
+func (c *Cache) load(ctx context.Context) error {
+	blobsDir := filepath.Join(c.root, ocispec.ImageBlobsDir)
+	return filepath.WalkDir(blobsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		algorithm := digest.Algorithm(filepath.Base(filepath.Dir(path)))
+		dgst := digest.NewDigestFromEncoded(algorithm, d.Name())
+		if dgst.Validate() != nil {
+			// not a blob
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// manifests are resolved with their media types recorded in the
+		// index, and other blobs with the default media type
+		desc, err := c.store.Resolve(ctx, dgst.String())
+		if err != nil {
+			return err
+		}
+		c.entries[dgst] = &entry{
+			desc:     desc,
+			lastUsed: info.ModTime(),
+		}
+		c.size += desc.Size
+		return nil
+	})
+}
+
+// Resolve resolves the digest reference to the descriptor of the cached
+// content. It returns errdef.ErrNotFound if the content is not cached.
+func (c *Cache) Resolve(_ context.Context, reference string) (ocispec.Descriptor, error) {
+	dgst, err := digest.Parse(reference)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", reference, errdef.ErrNotFound)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[dgst]
+	if !ok {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", reference, errdef.ErrNotFound)
+	}
+	c.touch(e)
+	return e.desc, nil
+}
+
+// Fetch fetches the cached content identified by the descriptor. It returns
+// errdef.ErrNotFound if the content is not cached.
+func (c *Cache) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	desc, err := c.Resolve(ctx, target.Digest.String())
+	if err != nil {
+		return nil, err
+	}
+	return c.store.Fetch(ctx, desc)
+}
+
+// FetchReference fetches the cached content identified by the digest
+// reference. It returns errdef.ErrNotFound if the content is not cached.
+func (c *Cache) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	desc, err := c.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	rc, err := c.store.Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, rc, nil
+}
+
+// Tee returns a reader of rc caching the content described by desc as it is
+// read. The content is only cached if it is read to the end and matches desc,
+// so that the caller controls how much of it is transferred. The reader is
+// seekable if rc is, and seeking stops the caching.
+func (c *Cache) Tee(ctx context.Context, desc ocispec.Descriptor, rc io.ReadCloser) io.ReadCloser {
+	if desc.Size > c.maxSize {
+		return rc
+	}
+	c.mu.Lock()
+	_, ok := c.entries[desc.Digest]
+	c.mu.Unlock()
+	if ok {
+		return rc
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the caller may be done with ctx before the content is cached
+		pr.CloseWithError(c.push(context.WithoutCancel(ctx), desc, pr))
+	}()
+	tee := &teeReadCloser{
+		rc:   rc,
+		pw:   pw,
+		done: done,
+	}
+	if _, ok := rc.(io.Seeker); ok {
+		return &teeReadSeekCloser{tee}
+	}
+	return tee
+}
+
+// push caches the content read from r and evicts the least recently used
+// content if the cache is full.
+func (c *Cache) push(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+	if err := c.store.Push(ctx, desc, r); err != nil {
+		if errors.Is(err, errdef.ErrAlreadyExists) {
+			return nil
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[desc.Digest]; !ok {
+		c.entries[desc.Digest] = &entry{
+			desc: ocispec.Descriptor{
+				MediaType: desc.MediaType,
+				Digest:    desc.Digest,
+				Size:      desc.Size,
+			},
+			lastUsed: time.Now(),
+		}
+		c.size += desc.Size
+	}
+	return c.evict(ctx)
+}
+
+// touch marks the entry as used. The caller must hold c.mu.
+func (c *Cache) touch(e *entry) {
+	e.lastUsed = time.Now()
+	// best effort as the modification time only matters across restarts
+	_ = os.Chtimes(c.path(e.desc.Digest), e.lastUsed, e.lastUsed)
+}
+
+// evict removes the least recently used content until the cache fits its
+// maximum size. The caller must hold c.mu.
+func (c *Cache) evict(ctx context.Context) error {
+	if c.size <= c.maxSize {
+		return nil
+	}
+	entries := make([]*entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+	for _, e := range entries {
+		if c.size <= c.maxSize {
+			break
+		}
+		if err := c.store.Delete(ctx, e.desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			return fmt.Errorf("failed to evict %s from cache: %w", e.desc.Digest, err)
+		}
+		delete(c.entries, e.desc.Digest)
+		c.size -= e.desc.Size
+	}
+	return nil
+}
+
+// path returns the path of the file of the content identified by dgst.
+func (c *Cache) path(dgst digest.Digest) string {
+	return filepath.Join(c.root, ocispec.ImageBlobsDir, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+// teeReadCloser writes the content read from rc to pw.
+type teeReadCloser struct {
+	rc   io.ReadCloser
+	pw   *io.PipeWriter
+	done chan struct{}
+}
+
+// teeReadSeekCloser is a teeReadCloser of a seekable rc, such as the content
+// fetched from a registry supporting range requests.
+type teeReadSeekCloser struct {
+	*teeReadCloser
+}
+
+// Seek aborts the caching, as the content is no longer read in full, and seeks
+// rc.
+func (t *teeReadSeekCloser) Seek(offset int64, whence int) (int64, error) {
+	if t.pw != nil {
+		t.pw.CloseWithError(errIncomplete)
+		t.pw = nil
+	}
+	return t.rc.(io.Seeker).Seek(offset, whence)
+}
+
+// Read reads from rc and writes the content read to pw until the content is
+// read to the end or caching fails.
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.rc.Read(p)
+	if n > 0 && t.pw != nil {
+		if _, werr := t.pw.Write(p[:n]); werr != nil {
+			// caching failed, keep reading without caching
+			t.pw = nil
+		}
+	}
+	if err == io.EOF && t.pw != nil {
+		t.pw.Close()
+		t.pw = nil
+	}
+	return n, err
+}
+
+// Close closes rc and waits for the content to be cached, aborting the caching
+// if the content is not read to the end.
+func (t *teeReadCloser) Close() error {
+	err := t.rc.Close()
+	if t.pw != nil {
+		t.pw.CloseWithError(errIncomplete)
+		t.pw = nil
+	}
+	<-t.done
+	return err
+}
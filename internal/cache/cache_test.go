@@ -0,0 +1,252 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// cacheBytes caches data through Tee, reading it to the end.
+func cacheBytes(t *testing.T, c *Cache, desc ocispec.Descriptor, data []byte) {
+	t.Helper()
+	rc := c.Tee(context.Background(), desc, io.NopCloser(bytes.NewReader(data)))
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read content: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Tee() read %q, want %q", got, data)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatalf("failed to close content: %v", err)
+	}
+}
+
+// readSeekNopCloser is an io.ReadSeeker with a no-op Close method.
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+// Close does nothing.
+func (readSeekNopCloser) Close() error {
+	return nil
+}
+
+// fetchBytes fetches the cached content by the digest reference.
+func fetchBytes(c *Cache, dgst digest.Digest) ([]byte, error) {
+	_, rc, err := c.FetchReference(context.Background(), dgst.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func TestCache_Tee(t *testing.T) {
+	ctx := context.Background()
+	c, err := New(ctx, t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	data := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("application/octet-stream", data)
+
+	if _, err := c.Resolve(ctx, desc.Digest.String()); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	cacheBytes(t, c, desc, data)
+
+	got, err := fetchBytes(c, desc.Digest)
+	if err != nil {
+		t.Fatalf("FetchReference() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("FetchReference() = %q, want %q", got, data)
+	}
+	rc, err := c.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	rc.Close()
+	if _, err := c.Resolve(ctx, "latest"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestCache_TeeSkipped(t *testing.T) {
+	ctx := context.Background()
+	c, err := New(ctx, t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Run("partial read", func(t *testing.T) {
+		data := []byte("partial")
+		desc := content.NewDescriptorFromBytes("application/octet-stream", data)
+		rc := c.Tee(ctx, desc, io.NopCloser(bytes.NewReader(data)))
+		if _, err := rc.Read(make([]byte, 3)); err != nil {
+			t.Fatalf("failed to read content: %v", err)
+		}
+		if err := rc.Close(); err != nil {
+			t.Fatalf("failed to close content: %v", err)
+		}
+		if _, err := fetchBytes(c, desc.Digest); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("FetchReference() error = %v, want %v", err, errdef.ErrNotFound)
+		}
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		data := []byte("seekable")
+		desc := content.NewDescriptorFromBytes("application/octet-stream", data)
+		rc := c.Tee(ctx, desc, readSeekNopCloser{bytes.NewReader(data)})
+		seeker, ok := rc.(io.Seeker)
+		if !ok {
+			t.Fatalf("Tee() = %T, want io.Seeker", rc)
+		}
+		if _, err := seeker.Seek(4, io.SeekStart); err != nil {
+			t.Fatalf("Seek() error = %v", err)
+		}
+		got, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read content: %v", err)
+		}
+		if string(got) != "able" {
+			t.Errorf("Tee() read %q after seeking, want %q", got, "able")
+		}
+		if err := rc.Close(); err != nil {
+			t.Fatalf("failed to close content: %v", err)
+		}
+		if _, err := fetchBytes(c, desc.Digest); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("FetchReference() error = %v, want %v", err, errdef.ErrNotFound)
+		}
+	})
+
+	t.Run("mismatched content", func(t *testing.T) {
+		desc := content.NewDescriptorFromBytes("application/octet-stream", []byte("expected"))
+		rc := c.Tee(ctx, desc, io.NopCloser(bytes.NewReader([]byte("tampered"))))
+		if _, err := io.ReadAll(rc); err != nil {
+			t.Fatalf("failed to read content: %v", err)
+		}
+		rc.Close()
+		if _, err := fetchBytes(c, desc.Digest); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("FetchReference() error = %v, want %v", err, errdef.ErrNotFound)
+		}
+	})
+
+	t.Run("oversized content", func(t *testing.T) {
+		data := []byte("too large to cache")
+		desc := content.NewDescriptorFromBytes("application/octet-stream", data)
+		cacheBytes(t, c, desc, data)
+		if _, err := fetchBytes(c, desc.Digest); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("FetchReference() error = %v, want %v", err, errdef.ErrNotFound)
+		}
+	})
+}
+
+func TestCache_Evict(t *testing.T) {
+	ctx := context.Background()
+	c, err := New(ctx, t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	blobs := [][]byte{[]byte("aaaa"), []byte("bbbb"), []byte("cccc")}
+	var descs []ocispec.Descriptor
+	for _, data := range blobs[:2] {
+		desc := content.NewDescriptorFromBytes("application/octet-stream", data)
+		descs = append(descs, desc)
+		cacheBytes(t, c, desc, data)
+		time.Sleep(time.Millisecond)
+	}
+	// use the first blob so that the second one is the least recently used
+	if _, err := fetchBytes(c, descs[0].Digest); err != nil {
+		t.Fatalf("FetchReference() error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	desc := content.NewDescriptorFromBytes("application/octet-stream", blobs[2])
+	descs = append(descs, desc)
+	cacheBytes(t, c, desc, blobs[2])
+
+	for i, want := range []bool{true, false, true} {
+		_, err := fetchBytes(c, descs[i].Digest)
+		if got := err == nil; got != want {
+			t.Errorf("blob %d cached = %v, want %v (error = %v)", i, got, want, err)
+		}
+	}
+	if c.size != 8 {
+		t.Errorf("size = %d, want 8", c.size)
+	}
+}
+
+func TestCache_Reload(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	c, err := New(ctx, root, 1024)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[]}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	blob := []byte("layer")
+	blobDesc := content.NewDescriptorFromBytes("application/vnd.oci.image.layer.v1.tar", blob)
+	cacheBytes(t, c, manifestDesc, manifest)
+	cacheBytes(t, c, blobDesc, blob)
+
+	// leave a stray file to be ignored
+	if err := os.WriteFile(filepath.Join(root, ocispec.ImageBlobsDir, "sha256", "stray"), nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// reopen with a size only fitting the manifest, which is recently used
+	if _, err := c.Resolve(ctx, manifestDesc.Digest.String()); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	reopened, err := New(ctx, root, manifestDesc.Size)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	desc, err := reopened.Resolve(ctx, manifestDesc.Digest.String())
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if desc.MediaType != ocispec.MediaTypeImageManifest || desc.Size != manifestDesc.Size {
+		t.Errorf("Resolve() = %+v, want %+v", desc, manifestDesc)
+	}
+	if _, err := reopened.Resolve(ctx, blobDesc.Digest.String()); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(root, nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := New(context.Background(), root, 1024); err == nil {
+		t.Fatalf("New() error = nil, want error")
+	}
+}
@@ -0,0 +1,389 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config loads the configuration of the oras-mcp server.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Environment variables overriding the configuration file.
+const (
+	EnvConfig        = "ORAS_MCP_CONFIG"
+	EnvPlainHTTP     = "ORAS_MCP_PLAIN_HTTP"
+	EnvSkipTLSVerify = "ORAS_MCP_SKIP_TLS_VERIFY"
+	EnvRetryMax      = "ORAS_MCP_RETRY_MAX"
+	EnvRetryMinWait  = "ORAS_MCP_RETRY_MIN_WAIT"
+	EnvRetryMaxWait  = "ORAS_MCP_RETRY_MAX_WAIT"
+	EnvMaxBlobSize   = "ORAS_MCP_MAX_BLOB_SIZE"
+	EnvTagCacheTTL   = "ORAS_MCP_TAG_CACHE_TTL"
+	EnvCacheDir      = "ORAS_MCP_CACHE_DIR"
+	EnvCacheMaxSize  = "ORAS_MCP_CACHE_MAX_SIZE"
+)
+
+// Config is the configuration of the oras-mcp server.
+type Config struct {
+	// Registry configures how registries are accessed.
+	Registry Registry `yaml:"registry"`
+	// Tool configures the behavior of the tools.
+	Tool Tool `yaml:"tool"`
+	// Cache configures the content cache.
+	Cache Cache `yaml:"cache"`
+}
+
+// Registry configures how registries are accessed.
+type Registry struct {
+	// PlainHTTP lists the registries accessed over plain HTTP. An entry is a
+	// host, which matches on any port, a host:port pair, or an IP range in
+	// CIDR notation.
+	PlainHTTP []string `yaml:"plainHTTP"`
+	// SkipTLSVerify lists the registries accessed over HTTPS without verifying
+	// their certificates, in the same format as PlainHTTP.
+	SkipTLSVerify []string `yaml:"skipTLSVerify"`
+	// CertsDirs lists the directories holding TLS certificates per registry in
+	// the Docker certs.d layout: CA bundles as <dir>/<host>/*.crt and client
+	// certificate pairs as <dir>/<host>/*.cert with the matching *.key.
+	CertsDirs []string `yaml:"certsDirs"`
+	// TLS lists the TLS certificates of individual registries.
+	TLS []RegistryTLS `yaml:"tls"`
+	// Mirrors lists the mirror and rewrite rules of repositories.
+	Mirrors []RegistryMirror `yaml:"mirrors"`
+	// TagCacheTTL is how long the descriptors resolved from tags are cached
+	// in memory. Zero disables the tag cache.
+	TagCacheTTL time.Duration `yaml:"tagCacheTTL"`
+	// Retry configures the retry of failed registry requests.
+	Retry Retry `yaml:"retry"`
+}
+
+// RegistryTLS configures the TLS certificates of a registry.
+type RegistryTLS struct {
+	// Registry is the host, which matches on any port, or the host:port pair
+	// of the registry.
+	Registry string `yaml:"registry"`
+	// CAFile is the path of the CA bundle to verify the registry against, in
+	// addition to the system roots.
+	CAFile string `yaml:"caFile"`
+	// CertFile is the path of the client certificate presented to the
+	// registry.
+	CertFile string `yaml:"certFile"`
+	// KeyFile is the path of the private key of the client certificate.
+	KeyFile string `yaml:"keyFile"`
+}
+
+// RegistryMirror configures the mirrors of the repositories under a prefix,
+// similar to the [[registry]] tables of containers-registries.conf. Locations
+// are in the same <registry>[/<repository>] format as the prefix, and the part
+// of a repository name following the prefix is appended to them.
+type RegistryMirror struct {
+	// Prefix is the registry, optionally followed by a repository namespace,
+	// of the repositories the rule applies to. The longest matching prefix
+	// wins.
+	Prefix string `yaml:"prefix"`
+	// Location rewrites the prefix of the upstream repositories. The prefix
+	// itself is used if empty.
+	Location string `yaml:"location"`
+	// Mirrors lists the locations tried in order before falling back to the
+	// upstream.
+	Mirrors []string `yaml:"mirrors"`
+}
+
+// Retry configures the retry of failed registry requests.
+type Retry struct {
+	// MaxRetry is the maximum number of retries. Zero disables retries.
+	MaxRetry int `yaml:"maxRetry"`
+	// MinWait is the minimum duration to wait before a retry.
+	MinWait time.Duration `yaml:"minWait"`
+	// MaxWait is the maximum duration to wait before a retry.
+	MaxWait time.Duration `yaml:"maxWait"`
+}
+
+// Tool configures the behavior of the tools.
+type Tool struct {
+	// MaxBlobSize is the maximum size in bytes of a blob fetched by the tools.
+	MaxBlobSize int64 `yaml:"maxBlobSize"`
+	// WellknownRegistries lists the registries returned by the
+	// list_wellknown_registries tool.
+	WellknownRegistries []WellknownRegistry `yaml:"wellknownRegistries"`
+}
+
+// WellknownRegistry describes a well-known registry.
+type WellknownRegistry struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// Cache configures the persistent content cache, which keeps the content
+// fetched by digest on disk across restarts.
+type Cache struct {
+	// Dir is the directory of the cache in the OCI image layout. Caching is
+	// disabled if empty.
+	Dir string `yaml:"dir"`
+	// MaxSize is the maximum size in bytes of the cached content, beyond which
+	// the least recently used content is evicted.
+	MaxSize int64 `yaml:"maxSize"`
+}
+
+// Default returns the default configuration.
+func Default() *Config {
+	return &Config{
+		Registry: Registry{
+			PlainHTTP:   []string{"localhost", "127.0.0.0/8", "::1/128"},
+			CertsDirs:   []string{"/etc/containers/certs.d", "/etc/docker/certs.d"},
+			TagCacheTTL: time.Minute,
+			Retry: Retry{
+				MaxRetry: 5,
+				MinWait:  200 * time.Millisecond,
+				MaxWait:  3 * time.Second,
+			},
+		},
+		Tool: Tool{
+			MaxBlobSize: 4 * 1024 * 1024, // 4 MiB
+			WellknownRegistries: []WellknownRegistry{
+				{
+					Name:        "mcr.microsoft.com",
+					Description: "Microsoft Container Registry",
+				},
+			},
+		},
+		Cache: Cache{
+			MaxSize: 1024 * 1024 * 1024, // 1 GiB
+		},
+	}
+}
+
+// Load loads the configuration from the YAML or JSON file at path on top of
+// the default configuration, applies the environment variable overrides, and
+// validates the result. The file is skipped if path is empty.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadFile decodes the configuration file at path into cfg. JSON files are
+// decoded as YAML, which is a superset of JSON.
+func (cfg *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open configuration file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode configuration file %q: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv applies the environment variable overrides to cfg.
+func (cfg *Config) loadEnv() error {
+	if value, ok := os.LookupEnv(EnvPlainHTTP); ok {
+		cfg.Registry.PlainHTTP = splitList(value)
+	}
+	if value, ok := os.LookupEnv(EnvSkipTLSVerify); ok {
+		cfg.Registry.SkipTLSVerify = splitList(value)
+	}
+	if value, ok := os.LookupEnv(EnvTagCacheTTL); ok {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvTagCacheTTL, err)
+		}
+		cfg.Registry.TagCacheTTL = ttl
+	}
+	if value, ok := os.LookupEnv(EnvRetryMax); ok {
+		maxRetry, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvRetryMax, err)
+		}
+		cfg.Registry.Retry.MaxRetry = maxRetry
+	}
+	if value, ok := os.LookupEnv(EnvRetryMinWait); ok {
+		minWait, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvRetryMinWait, err)
+		}
+		cfg.Registry.Retry.MinWait = minWait
+	}
+	if value, ok := os.LookupEnv(EnvRetryMaxWait); ok {
+		maxWait, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvRetryMaxWait, err)
+		}
+		cfg.Registry.Retry.MaxWait = maxWait
+	}
+	if value, ok := os.LookupEnv(EnvMaxBlobSize); ok {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvMaxBlobSize, err)
+		}
+		cfg.Tool.MaxBlobSize = size
+	}
+	if value, ok := os.LookupEnv(EnvCacheDir); ok {
+		cfg.Cache.Dir = value
+	}
+	if value, ok := os.LookupEnv(EnvCacheMaxSize); ok {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvCacheMaxSize, err)
+		}
+		cfg.Cache.MaxSize = size
+	}
+	return nil
+}
+
+// Validate validates the configuration.
+func (cfg *Config) Validate() error {
+	if err := validateRegistryPatterns(cfg.Registry.PlainHTTP); err != nil {
+		return fmt.Errorf("registry.plainHTTP: %w", err)
+	}
+	if err := validateRegistryPatterns(cfg.Registry.SkipTLSVerify); err != nil {
+		return fmt.Errorf("registry.skipTLSVerify: %w", err)
+	}
+	for _, entry := range cfg.Registry.TLS {
+		if entry.Registry == "" {
+			return errors.New("registry.tls: empty registry name")
+		}
+		if (entry.CertFile == "") != (entry.KeyFile == "") {
+			return fmt.Errorf("registry.tls: both certFile and keyFile are required for %s", entry.Registry)
+		}
+		if entry.CAFile == "" && entry.CertFile == "" {
+			return fmt.Errorf("registry.tls: no certificates configured for %s", entry.Registry)
+		}
+	}
+	for _, mirror := range cfg.Registry.Mirrors {
+		if err := validateMirror(mirror); err != nil {
+			return fmt.Errorf("registry.mirrors: %w", err)
+		}
+	}
+	if cfg.Registry.TagCacheTTL < 0 {
+		return fmt.Errorf("registry.tagCacheTTL: must not be negative, got %v", cfg.Registry.TagCacheTTL)
+	}
+	retry := cfg.Registry.Retry
+	if retry.MaxRetry < 0 {
+		return fmt.Errorf("registry.retry.maxRetry: must not be negative, got %d", retry.MaxRetry)
+	}
+	if retry.MinWait < 0 {
+		return fmt.Errorf("registry.retry.minWait: must not be negative, got %v", retry.MinWait)
+	}
+	if retry.MaxWait < retry.MinWait {
+		return fmt.Errorf("registry.retry.maxWait: must not be less than minWait %v, got %v", retry.MinWait, retry.MaxWait)
+	}
+	if cfg.Tool.MaxBlobSize <= 0 {
+		return fmt.Errorf("tool.maxBlobSize: must be positive, got %d", cfg.Tool.MaxBlobSize)
+	}
+	for _, reg := range cfg.Tool.WellknownRegistries {
+		ref := registry.Reference{Registry: reg.Name}
+		if err := ref.ValidateRegistry(); err != nil {
+			return fmt.Errorf("tool.wellknownRegistries: %w", err)
+		}
+	}
+	if cfg.Cache.MaxSize <= 0 {
+		return fmt.Errorf("cache.maxSize: must be positive, got %d", cfg.Cache.MaxSize)
+	}
+	return nil
+}
+
+// validateRegistryPatterns validates a list of registry names and CIDR ranges.
+func validateRegistryPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return errors.New("empty registry name")
+		}
+		if strings.Contains(pattern, "/") {
+			if _, err := netip.ParsePrefix(pattern); err != nil {
+				return fmt.Errorf("invalid CIDR range: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMirror validates the prefix and the locations of a mirror rule.
+func validateMirror(mirror RegistryMirror) error {
+	hasRepository, err := validateLocation(mirror.Prefix)
+	if err != nil {
+		return fmt.Errorf("invalid prefix %q: %w", mirror.Prefix, err)
+	}
+	locations := mirror.Mirrors
+	if mirror.Location != "" {
+		locations = append([]string{mirror.Location}, locations...)
+	}
+	for _, location := range locations {
+		locationHasRepository, err := validateLocation(location)
+		if err != nil {
+			return fmt.Errorf("invalid location %q of %s: %w", location, mirror.Prefix, err)
+		}
+		if hasRepository && !locationHasRepository {
+			return fmt.Errorf("location %q of %s must include a repository", location, mirror.Prefix)
+		}
+	}
+	return nil
+}
+
+// validateLocation validates a location in the <registry>[/<repository>]
+// format and reports whether it includes a repository.
+func validateLocation(location string) (bool, error) {
+	if location == "" {
+		return false, errors.New("empty location")
+	}
+	name, repository, hasRepository := strings.Cut(location, "/")
+	ref := registry.Reference{
+		Registry:   name,
+		Repository: repository,
+	}
+	if err := ref.ValidateRegistry(); err != nil {
+		return false, err
+	}
+	if hasRepository {
+		if err := ref.ValidateRepository(); err != nil {
+			return false, err
+		}
+	}
+	return hasRepository, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
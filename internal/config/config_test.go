@@ -0,0 +1,426 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to a temporary file and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	return path
+}
+
+// clearEnv unsets the override environment variables for the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{EnvPlainHTTP, EnvSkipTLSVerify, EnvTagCacheTTL, EnvRetryMax, EnvRetryMinWait, EnvRetryMaxWait, EnvMaxBlobSize, EnvCacheDir, EnvCacheMaxSize} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_Default(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("Load() = %+v, want default configuration", cfg)
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", `
+registry:
+  plainHTTP:
+    - registry.internal:5000
+    - 10.0.0.0/8
+  skipTLSVerify:
+    - registry.insecure
+  certsDirs:
+    - /etc/oras-mcp/certs.d
+  tls:
+    - registry: registry.corp
+      caFile: /etc/ssl/corp-ca.pem
+      certFile: /etc/ssl/client.pem
+      keyFile: /etc/ssl/client-key.pem
+  mirrors:
+    - prefix: docker.io
+      mirrors:
+        - mirror.corp/dockerhub
+    - prefix: quay.io/team
+      location: registry.corp/team
+  tagCacheTTL: 30s
+  retry:
+    maxRetry: 2
+    minWait: 100ms
+    maxWait: 1s
+tool:
+  maxBlobSize: 1024
+  wellknownRegistries:
+    - name: ghcr.io
+      description: GitHub Container Registry
+cache:
+  dir: /var/cache/oras-mcp
+  maxSize: 2048
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := &Config{
+		Registry: Registry{
+			PlainHTTP:     []string{"registry.internal:5000", "10.0.0.0/8"},
+			SkipTLSVerify: []string{"registry.insecure"},
+			CertsDirs:     []string{"/etc/oras-mcp/certs.d"},
+			TLS: []RegistryTLS{
+				{
+					Registry: "registry.corp",
+					CAFile:   "/etc/ssl/corp-ca.pem",
+					CertFile: "/etc/ssl/client.pem",
+					KeyFile:  "/etc/ssl/client-key.pem",
+				},
+			},
+			Mirrors: []RegistryMirror{
+				{
+					Prefix:  "docker.io",
+					Mirrors: []string{"mirror.corp/dockerhub"},
+				},
+				{
+					Prefix:   "quay.io/team",
+					Location: "registry.corp/team",
+				},
+			},
+			TagCacheTTL: 30 * time.Second,
+			Retry: Retry{
+				MaxRetry: 2,
+				MinWait:  100 * time.Millisecond,
+				MaxWait:  time.Second,
+			},
+		},
+		Tool: Tool{
+			MaxBlobSize: 1024,
+			WellknownRegistries: []WellknownRegistry{
+				{Name: "ghcr.io", Description: "GitHub Container Registry"},
+			},
+		},
+		Cache: Cache{
+			Dir:     "/var/cache/oras-mcp",
+			MaxSize: 2048,
+		},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("Load() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.json", `{"tool": {"maxBlobSize": 2048}}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Tool.MaxBlobSize != 2048 {
+		t.Errorf("MaxBlobSize = %d, want 2048", cfg.Tool.MaxBlobSize)
+	}
+	// unspecified settings keep their defaults
+	if !reflect.DeepEqual(cfg.Registry, Default().Registry) {
+		t.Errorf("Registry = %+v, want default", cfg.Registry)
+	}
+	if !reflect.DeepEqual(cfg.Tool.WellknownRegistries, Default().Tool.WellknownRegistries) {
+		t.Errorf("WellknownRegistries = %+v, want default", cfg.Tool.WellknownRegistries)
+	}
+}
+
+func TestLoad_EmptyFile(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", "")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("Load() = %+v, want default configuration", cfg)
+	}
+}
+
+func TestLoad_Env(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", `
+registry:
+  plainHTTP: [registry.internal]
+tool:
+  maxBlobSize: 1024
+`)
+	t.Setenv(EnvPlainHTTP, "localhost, registry.local:5000,")
+	t.Setenv(EnvSkipTLSVerify, "registry.insecure")
+	t.Setenv(EnvTagCacheTTL, "0s")
+	t.Setenv(EnvRetryMax, "0")
+	t.Setenv(EnvRetryMinWait, "0s")
+	t.Setenv(EnvRetryMaxWait, "0s")
+	t.Setenv(EnvMaxBlobSize, "4096")
+	t.Setenv(EnvCacheDir, "/tmp/oras-mcp")
+	t.Setenv(EnvCacheMaxSize, "8192")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"localhost", "registry.local:5000"}; !reflect.DeepEqual(cfg.Registry.PlainHTTP, want) {
+		t.Errorf("PlainHTTP = %v, want %v", cfg.Registry.PlainHTTP, want)
+	}
+	if want := []string{"registry.insecure"}; !reflect.DeepEqual(cfg.Registry.SkipTLSVerify, want) {
+		t.Errorf("SkipTLSVerify = %v, want %v", cfg.Registry.SkipTLSVerify, want)
+	}
+	if cfg.Registry.TagCacheTTL != 0 {
+		t.Errorf("TagCacheTTL = %v, want 0s", cfg.Registry.TagCacheTTL)
+	}
+	if want := (Retry{}); cfg.Registry.Retry != want {
+		t.Errorf("Retry = %+v, want %+v", cfg.Registry.Retry, want)
+	}
+	if cfg.Tool.MaxBlobSize != 4096 {
+		t.Errorf("MaxBlobSize = %d, want 4096", cfg.Tool.MaxBlobSize)
+	}
+	if want := (Cache{Dir: "/tmp/oras-mcp", MaxSize: 8192}); cfg.Cache != want {
+		t.Errorf("Cache = %+v, want %+v", cfg.Cache, want)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			content: "registry:\n  plainHttp: [localhost]\n",
+			wantErr: "failed to decode",
+		},
+		{
+			name:    "malformed file",
+			content: "registry: [",
+			wantErr: "failed to decode",
+		},
+		{
+			name:    "invalid duration",
+			content: "registry:\n  retry:\n    minWait: soon\n",
+			wantErr: "failed to decode",
+		},
+		{
+			name:    "invalid env retry",
+			env:     map[string]string{EnvRetryMax: "many"},
+			wantErr: EnvRetryMax,
+		},
+		{
+			name:    "invalid env min wait",
+			env:     map[string]string{EnvRetryMinWait: "soon"},
+			wantErr: EnvRetryMinWait,
+		},
+		{
+			name:    "invalid env max wait",
+			env:     map[string]string{EnvRetryMaxWait: "later"},
+			wantErr: EnvRetryMaxWait,
+		},
+		{
+			name:    "invalid env blob size",
+			env:     map[string]string{EnvMaxBlobSize: "4MiB"},
+			wantErr: EnvMaxBlobSize,
+		},
+		{
+			name:    "invalid env tag cache TTL",
+			env:     map[string]string{EnvTagCacheTTL: "forever"},
+			wantErr: EnvTagCacheTTL,
+		},
+		{
+			name:    "invalid env cache size",
+			env:     map[string]string{EnvCacheMaxSize: "1GiB"},
+			wantErr: EnvCacheMaxSize,
+		},
+		{
+			name:    "validation failure",
+			content: "tool:\n  maxBlobSize: 0\n",
+			wantErr: "invalid configuration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			path := ""
+			if tt.content != "" {
+				path = writeFile(t, "config.yaml", tt.content)
+			}
+			_, err := Load(path)
+			if err == nil {
+				t.Fatalf("Load() error = nil, want error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Load() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	clearEnv(t)
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("Load() error = nil, want error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{
+			name: "empty plain HTTP registry",
+			modify: func(cfg *Config) {
+				cfg.Registry.PlainHTTP = []string{""}
+			},
+		},
+		{
+			name: "invalid plain HTTP CIDR range",
+			modify: func(cfg *Config) {
+				cfg.Registry.PlainHTTP = []string{"10.0.0.0/33"}
+			},
+		},
+		{
+			name: "empty skip TLS verify registry",
+			modify: func(cfg *Config) {
+				cfg.Registry.SkipTLSVerify = []string{""}
+			},
+		},
+		{
+			name: "TLS entry without registry",
+			modify: func(cfg *Config) {
+				cfg.Registry.TLS = []RegistryTLS{{CAFile: "ca.pem"}}
+			},
+		},
+		{
+			name: "TLS entry without key",
+			modify: func(cfg *Config) {
+				cfg.Registry.TLS = []RegistryTLS{{Registry: "registry.corp", CertFile: "client.pem"}}
+			},
+		},
+		{
+			name: "TLS entry without certificates",
+			modify: func(cfg *Config) {
+				cfg.Registry.TLS = []RegistryTLS{{Registry: "registry.corp"}}
+			},
+		},
+		{
+			name: "mirror without prefix",
+			modify: func(cfg *Config) {
+				cfg.Registry.Mirrors = []RegistryMirror{{Mirrors: []string{"mirror.corp"}}}
+			},
+		},
+		{
+			name: "mirror with invalid prefix",
+			modify: func(cfg *Config) {
+				cfg.Registry.Mirrors = []RegistryMirror{{Prefix: "docker.io/Library", Mirrors: []string{"mirror.corp/library"}}}
+			},
+		},
+		{
+			name: "mirror with invalid location",
+			modify: func(cfg *Config) {
+				cfg.Registry.Mirrors = []RegistryMirror{{Prefix: "docker.io", Mirrors: []string{"mirror corp"}}}
+			},
+		},
+		{
+			name: "mirror location without repository",
+			modify: func(cfg *Config) {
+				cfg.Registry.Mirrors = []RegistryMirror{{Prefix: "docker.io/library", Location: "registry.corp"}}
+			},
+		},
+		{
+			name: "non-positive cache size",
+			modify: func(cfg *Config) {
+				cfg.Cache.MaxSize = 0
+			},
+		},
+		{
+			name: "negative tag cache TTL",
+			modify: func(cfg *Config) {
+				cfg.Registry.TagCacheTTL = -time.Second
+			},
+		},
+		{
+			name: "negative max retry",
+			modify: func(cfg *Config) {
+				cfg.Registry.Retry.MaxRetry = -1
+			},
+		},
+		{
+			name: "negative min wait",
+			modify: func(cfg *Config) {
+				cfg.Registry.Retry.MinWait = -time.Second
+			},
+		},
+		{
+			name: "max wait less than min wait",
+			modify: func(cfg *Config) {
+				cfg.Registry.Retry.MaxWait = cfg.Registry.Retry.MinWait - time.Millisecond
+			},
+		},
+		{
+			name: "non-positive max blob size",
+			modify: func(cfg *Config) {
+				cfg.Tool.MaxBlobSize = -1
+			},
+		},
+		{
+			name: "invalid well-known registry",
+			modify: func(cfg *Config) {
+				cfg.Tool.WellknownRegistries = []WellknownRegistry{{Name: "https://example.com"}}
+			},
+		},
+	}
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Validate() default error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Fatalf("Validate() error = nil, want error")
+			}
+		})
+	}
+}
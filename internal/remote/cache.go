@@ -0,0 +1,62 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/cache"
+)
+
+// contentCache is the content cache consulted by repositories for content
+// identified by digests. Nil disables caching.
+var contentCache *cache.Cache
+
+// SetCache sets the content cache shared by all repositories. A nil cache
+// disables caching. It must not be called concurrently with registry requests.
+func SetCache(c *cache.Cache) {
+	contentCache = c
+}
+
+// fetchCached fetches the cached content identified by the digest reference.
+// It reports false if the reference is not a digest or the content is not
+// cached. As the cache is shared by all sessions, callers confirm that the
+// content is accessible with their credentials before serving it.
+func fetchCached(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, bool) {
+	if contentCache == nil {
+		return ocispec.Descriptor{}, nil, false
+	}
+	if _, err := digest.Parse(reference); err != nil {
+		return ocispec.Descriptor{}, nil, false
+	}
+	desc, rc, err := contentCache.FetchReference(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, false
+	}
+	return desc, rc, true
+}
+
+// cacheContent returns a reader of rc caching the content described by desc
+// as it is read.
+func cacheContent(ctx context.Context, desc ocispec.Descriptor, rc io.ReadCloser) io.ReadCloser {
+	if contentCache == nil {
+		return rc
+	}
+	return contentCache.Tee(ctx, desc, rc)
+}
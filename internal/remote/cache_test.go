@@ -0,0 +1,307 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/cache"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// TestRepository_Cache tests that content identified by digests is served by
+// the content cache, with only its existence confirmed by the registry.
+func TestRepository_Cache(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	blob := []byte(`{"architecture":"amd64"}`)
+	blobDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, blob)
+
+	var requests, heads atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		} else {
+			requests.Add(1)
+		}
+		switch r.URL.Path {
+		case "/v2/test-repo/manifests/v1", "/v2/test-repo/manifests/" + manifestDesc.Digest.String():
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+			w.Write(manifest)
+		case "/v2/test-repo/blobs/" + blobDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+			w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := cache.New(context.Background(), t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	SetCache(c)
+	t.Cleanup(func() {
+		SetCache(nil)
+	})
+
+	ctx := context.Background()
+	repo := NewRepository(ctx, registry.Reference{
+		Registry:   strings.TrimPrefix(ts.URL, "http://"),
+		Repository: "test-repo",
+	})
+	readAll := func(rc io.ReadCloser, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		return string(data)
+	}
+	fetchManifest := func(reference string) string {
+		_, rc, err := repo.FetchReference(ctx, reference)
+		return readAll(rc, err)
+	}
+	fetchBlob := func() string {
+		_, rc, err := repo.Blobs().FetchReference(ctx, blobDesc.Digest.String())
+		return readAll(rc, err)
+	}
+
+	// the manifest fetched by tag is cached by its digest
+	if got := fetchManifest("v1"); got != string(manifest) {
+		t.Fatalf("FetchReference() = %s, want %s", got, manifest)
+	}
+	if got := fetchManifest(manifestDesc.Digest.String()); got != string(manifest) {
+		t.Fatalf("FetchReference() = %s, want %s", got, manifest)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	if got := heads.Load(); got != 1 {
+		t.Errorf("HEAD requests = %d, want 1", got)
+	}
+
+	// tags are fetched from the registry when refreshing
+	repo.Refresh = true
+	fetchManifest("v1")
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	// blobs are cached on the first fetch
+	for range 2 {
+		if got := fetchBlob(); got != string(blob) {
+			t.Fatalf("Blobs().FetchReference() = %s, want %s", got, blob)
+		}
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if got := readAll(repo.Fetch(ctx, blobDesc)); got != string(blob) {
+		t.Fatalf("Fetch() = %s, want %s", got, blob)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if got := heads.Load(); got != 3 {
+		t.Errorf("HEAD requests = %d, want 3", got)
+	}
+}
+
+// TestRepository_CacheAccess tests that the content cached for a session is
+// not served to another session without access to it.
+func TestRepository_CacheAccess(t *testing.T) {
+	blob := []byte("private")
+	blobDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.Header().Set("Www-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/private/manifests/" + manifestDesc.Digest.String():
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+			w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+			w.Write(manifest)
+		case "/v2/private/blobs/" + blobDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+			w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := cache.New(context.Background(), t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	SetCache(c)
+	t.Cleanup(func() {
+		SetCache(nil)
+	})
+
+	ref := registry.Reference{
+		Registry:   strings.TrimPrefix(ts.URL, "http://"),
+		Repository: "private",
+	}
+	sessionCredential := func(username, password string) auth.CredentialFunc {
+		return auth.StaticCredential(ref.Registry, auth.Credential{
+			Username: username,
+			Password: password,
+		})
+	}
+	owner := NewRepository(WithClient(context.Background(), NewClient(sessionCredential("user", "secret"))), ref)
+	ctx := WithClient(context.Background(), NewClient(sessionCredential("", "")))
+	other := NewRepository(ctx, ref)
+	ref.Repository = "public"
+	elsewhere := NewRepository(ctx, ref)
+
+	// the owner caches the content
+	for _, fetch := range []func(*Repository) (io.ReadCloser, error){
+		func(repo *Repository) (io.ReadCloser, error) {
+			_, rc, err := repo.FetchReference(context.Background(), manifestDesc.Digest.String())
+			return rc, err
+		},
+		func(repo *Repository) (io.ReadCloser, error) {
+			_, rc, err := repo.Blobs().FetchReference(context.Background(), blobDesc.Digest.String())
+			return rc, err
+		},
+	} {
+		rc, err := fetch(owner)
+		if err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		if _, err := io.ReadAll(rc); err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		rc.Close()
+	}
+	if _, err := c.Resolve(context.Background(), manifestDesc.Digest.String()); err != nil {
+		t.Fatal("manifest is not cached")
+	}
+	if _, err := c.Resolve(context.Background(), blobDesc.Digest.String()); err != nil {
+		t.Fatal("blob is not cached")
+	}
+
+	// other sessions without access are refused by the registry
+	for _, repo := range []*Repository{other, elsewhere} {
+		if _, _, err := repo.FetchReference(context.Background(), manifestDesc.Digest.String()); err == nil {
+			t.Errorf("FetchReference() error = nil, want error")
+		}
+		if _, err := repo.Fetch(context.Background(), manifestDesc); err == nil {
+			t.Errorf("Fetch() error = nil, want error")
+		}
+		if _, _, err := repo.Blobs().FetchReference(context.Background(), blobDesc.Digest.String()); err == nil {
+			t.Errorf("Blobs().FetchReference() error = nil, want error")
+		}
+		if _, err := repo.Blobs().Fetch(context.Background(), blobDesc); err == nil {
+			t.Errorf("Blobs().Fetch() error = nil, want error")
+		}
+	}
+}
+
+// TestRepository_CacheMediaType tests that a manifest cached as a blob is
+// fetched as a manifest with the media type reported by the registry.
+func TestRepository_CacheMediaType(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, manifest)
+
+	var manifestHeads atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/test-repo/manifests/" + manifestDesc.Digest.String():
+			if r.Method == http.MethodHead {
+				manifestHeads.Add(1)
+			}
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+			w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+		case "/v2/test-repo/blobs/" + manifestDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+		if r.Method == http.MethodGet {
+			w.Write(manifest)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := cache.New(context.Background(), t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	SetCache(c)
+	t.Cleanup(func() {
+		SetCache(nil)
+	})
+
+	ctx := context.Background()
+	repo := NewRepository(ctx, registry.Reference{
+		Registry:   strings.TrimPrefix(ts.URL, "http://"),
+		Repository: "test-repo",
+	})
+
+	// the manifest is cached through the blob endpoint
+	desc, rc, err := repo.Blobs().FetchReference(ctx, manifestDesc.Digest.String())
+	if err != nil {
+		t.Fatalf("Blobs().FetchReference() error = %v", err)
+	}
+	if desc.MediaType != "application/octet-stream" {
+		t.Errorf("Blobs().FetchReference() media type = %s, want application/octet-stream", desc.MediaType)
+	}
+	if _, err := io.ReadAll(rc); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	rc.Close()
+
+	desc, rc, err = repo.FetchReference(ctx, manifestDesc.Digest.String())
+	if err != nil {
+		t.Fatalf("FetchReference() error = %v", err)
+	}
+	rc.Close()
+	if !content.Equal(desc, manifestDesc) {
+		t.Errorf("FetchReference() = %+v, want %+v", desc, manifestDesc)
+	}
+	if got := manifestHeads.Load(); got != 1 {
+		t.Errorf("HEAD requests to the manifest = %d, want 1", got)
+	}
+}
@@ -16,9 +16,13 @@ limitations under the License.
 package remote
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 
+	"github.com/oras-project/oras-mcp/internal/config"
 	"github.com/oras-project/oras-mcp/internal/version"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/credentials"
@@ -31,6 +35,12 @@ import (
 // mode.
 var DefaultClient *auth.Client
 
+// registryConfig is the configuration of registry access.
+var registryConfig = config.Default().Registry
+
+// baseTransport is the transport shared by all auth clients.
+var baseTransport = newTransport(registryConfig)
+
 func init() {
 	var err error
 	DefaultClient, err = authClient()
@@ -39,28 +49,105 @@ func init() {
 	}
 }
 
+// Configure applies the configuration of registry access and rebuilds
+// DefaultClient accordingly. It must not be called concurrently with registry
+// requests.
+func Configure(cfg config.Registry) {
+	registryConfig = cfg
+	baseTransport = newTransport(cfg)
+	tags = newTagCache(cfg.TagCacheTTL)
+	DefaultClient = NewClient(DefaultClient.Credential)
+}
+
 // isPlainHttp determines whether to use plain HTTP for the given registry.
 func isPlainHttp(registry string) bool {
-	host, _, _ := net.SplitHostPort(registry)
-	return host == "localhost" || registry == "localhost"
+	return matchRegistry(registryConfig.PlainHTTP, registry)
+}
+
+// matchRegistry reports whether the registry matches any of the patterns. A
+// pattern is a host, which matches on any port, a host:port pair, or an IP
+// range in CIDR notation.
+func matchRegistry(patterns []string, registry string) bool {
+	host, _, err := net.SplitHostPort(registry)
+	if err != nil {
+		host = registry
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	ip, ipErr := netip.ParseAddr(host)
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			// invalid prefixes are rejected by the configuration validation
+			prefix, err := netip.ParsePrefix(pattern)
+			if err == nil && ipErr == nil && prefix.Contains(ip.Unmap()) {
+				return true
+			}
+			continue
+		}
+		if pattern == registry || strings.TrimSuffix(strings.TrimPrefix(pattern, "["), "]") == host {
+			return true
+		}
+	}
+	return false
+}
+
+// retryTransport wraps base with the configured retry policy.
+func retryTransport(base http.RoundTripper) http.RoundTripper {
+	policy := &retry.GenericPolicy{
+		Retryable: retry.DefaultPredicate,
+		Backoff:   retry.DefaultBackoff,
+		MinWait:   registryConfig.Retry.MinWait,
+		MaxWait:   registryConfig.Retry.MaxWait,
+		MaxRetry:  registryConfig.Retry.MaxRetry,
+	}
+	return &retry.Transport{
+		Base: base,
+		Policy: func() retry.Policy {
+			return policy
+		},
+	}
 }
 
-// authClient assembles an oras-mcp auth client.
+// authClient assembles an oras-mcp auth client with credentials from the
+// Docker credential store.
 func authClient() (*auth.Client, error) {
+	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(credentials.Credential(store)), nil
+}
+
+// NewClient assembles an oras-mcp auth client resolving credentials with the
+// given function. Each client has its own token cache so that tokens are never
+// shared across clients.
+func NewClient(credential auth.CredentialFunc) *auth.Client {
 	client := &auth.Client{
 		Client: &http.Client{
-			// http.RoundTripper with a retry using the DefaultPolicy
+			// http.RoundTripper with a retry using the configured policy
 			// see: https://pkg.go.dev/oras.land/oras-go/v2/registry/remote/retry#Policy
-			Transport: retry.NewTransport(http.DefaultTransport),
+			Transport: retryTransport(baseTransport),
 		},
-		Cache: auth.NewCache(),
+		Cache:      auth.NewCache(),
+		Credential: credential,
 	}
 	client.SetUserAgent("oras-mcp/" + version.GetVersion())
+	return client
+}
 
-	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
-	if err != nil {
-		return nil, err
+// clientContextKey is the context key of the auth client.
+type clientContextKey struct{}
+
+// WithClient returns a copy of ctx carrying the auth client to access
+// registries with.
+func WithClient(ctx context.Context, client *auth.Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns the auth client carried by ctx, or DefaultClient
+// if there is none.
+func ClientFromContext(ctx context.Context) *auth.Client {
+	if client, ok := ctx.Value(clientContextKey{}).(*auth.Client); ok && client != nil {
+		return client
 	}
-	client.Credential = credentials.Credential(store)
-	return client, nil
+	return DefaultClient
 }
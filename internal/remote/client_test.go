@@ -16,10 +16,14 @@ limitations under the License.
 package remote
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/oras-project/oras-mcp/internal/config"
 	"github.com/oras-project/oras-mcp/internal/version"
 	"oras.land/oras-go/v2/registry/remote/auth"
 )
@@ -56,6 +60,16 @@ func TestIsPlainHttp(t *testing.T) {
 			registry: "192.168.1.1:5000",
 			want:     false,
 		},
+		{
+			name:     "loopback IPv4 with port",
+			registry: "127.0.0.1:5000",
+			want:     true,
+		},
+		{
+			name:     "loopback IPv6 with port",
+			registry: "[::1]:5000",
+			want:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -161,3 +175,147 @@ func TestDefaultClient(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
 	}
 }
+
+// TestNewClient checks that each client resolves its own credentials and owns
+// its own token cache.
+func TestNewClient(t *testing.T) {
+	credential := auth.StaticCredential("localhost:5000", auth.Credential{
+		Username: "user",
+		Password: "pass",
+	})
+	client := NewClient(credential)
+	other := NewClient(credential)
+
+	if client.Cache == nil {
+		t.Fatal("Expected Cache to be initialized")
+	}
+	if client.Cache == other.Cache {
+		t.Error("Expected clients to have their own caches")
+	}
+	got, err := client.Credential(context.Background(), "localhost:5000")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if got.Username != "user" || got.Password != "pass" {
+		t.Errorf("Credential() = %+v, want user/pass", got)
+	}
+}
+
+// TestClientFromContext tests the context propagation of auth clients.
+func TestClientFromContext(t *testing.T) {
+	if got := ClientFromContext(context.Background()); got != DefaultClient {
+		t.Errorf("ClientFromContext() = %v, want DefaultClient", got)
+	}
+
+	client := NewClient(nil)
+	ctx := WithClient(context.Background(), client)
+	if got := ClientFromContext(ctx); got != client {
+		t.Errorf("ClientFromContext() = %v, want the client in context", got)
+	}
+
+	ctx = WithClient(context.Background(), nil)
+	if got := ClientFromContext(ctx); got != DefaultClient {
+		t.Errorf("ClientFromContext() = %v, want DefaultClient for nil client", got)
+	}
+}
+
+// TestConfigure tests that the registry configuration is applied.
+func TestConfigure(t *testing.T) {
+	original := DefaultClient
+	t.Cleanup(func() {
+		Configure(config.Default().Registry)
+		DefaultClient = original
+	})
+
+	Configure(config.Registry{
+		PlainHTTP:     []string{"registry.internal:5000", "registry.local", "10.0.0.0/8", "[fd00::1]"},
+		SkipTLSVerify: []string{"registry.insecure"},
+		Retry: config.Retry{
+			MaxRetry: 1,
+			MinWait:  time.Millisecond,
+			MaxWait:  time.Millisecond,
+		},
+	})
+
+	if DefaultClient == original {
+		t.Error("Expected DefaultClient to be rebuilt")
+	}
+	if DefaultClient.Credential == nil {
+		t.Error("Expected DefaultClient to keep its credential function")
+	}
+
+	if !matchRegistry(baseTransport.cfg.SkipTLSVerify, "registry.insecure") {
+		t.Error("Expected base transport to skip TLS verification of the configured registries")
+	}
+
+	plainHTTP := map[string]bool{
+		"registry.internal:5000": true,
+		"registry.internal:5001": false,
+		"registry.local":         true,
+		"registry.local:8080":    true,
+		"localhost:5000":         false,
+		"10.1.2.3:5000":          true,
+		"11.1.2.3":               false,
+		"[fd00::1]:5000":         true,
+	}
+	for registry, want := range plainHTTP {
+		if got := isPlainHttp(registry); got != want {
+			t.Errorf("isPlainHttp(%q) = %v, want %v", registry, got, want)
+		}
+	}
+
+	// the request is retried once
+	var count atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make test request: %v", err)
+	}
+	resp.Body.Close()
+	if got := count.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+// TestMatchRegistry tests the matchRegistry function.
+func TestMatchRegistry(t *testing.T) {
+	patterns := []string{
+		"registry.internal:5000",
+		"registry.local",
+		"192.168.0.0/16",
+		"fd00::/8",
+		"[::1]",
+	}
+	tests := []struct {
+		registry string
+		want     bool
+	}{
+		{registry: "registry.internal:5000", want: true},
+		{registry: "registry.internal", want: false},
+		{registry: "registry.internal:5001", want: false},
+		{registry: "registry.local", want: true},
+		{registry: "registry.local:443", want: true},
+		{registry: "192.168.1.1", want: true},
+		{registry: "192.168.1.1:5000", want: true},
+		{registry: "192.169.1.1:5000", want: false},
+		{registry: "[fd12::1]:5000", want: true},
+		{registry: "[fe80::1]:5000", want: false},
+		{registry: "[::1]:5000", want: true},
+		{registry: "example.com", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			if got := matchRegistry(patterns, tt.registry); got != tt.want {
+				t.Errorf("matchRegistry(%q) = %v, want %v", tt.registry, got, tt.want)
+			}
+		})
+	}
+}
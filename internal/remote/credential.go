@@ -0,0 +1,73 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// RegistryAuthHeader is the HTTP header through which MCP clients forward
+// their registry credentials. Each header value is a comma-separated list of
+// `<registry>=<base64(username:password)>` entries.
+const RegistryAuthHeader = "X-Registry-Auth"
+
+// dockerHubHost is the host that Docker Hub is accessed through, which the
+// credentials forwarded for docker.io and index.docker.io apply to, as with
+// the Docker credential store.
+const dockerHubHost = "registry-1.docker.io"
+
+// CredentialFromHeader returns a credential function resolving the registry
+// credentials forwarded in the header. Registries without forwarded
+// credentials are accessed anonymously.
+func CredentialFromHeader(header http.Header) (auth.CredentialFunc, error) {
+	creds := make(map[string]auth.Credential)
+	for _, value := range header.Values(RegistryAuthHeader) {
+		for entry := range strings.SplitSeq(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			registry, encoded, ok := strings.Cut(entry, "=")
+			if !ok || registry == "" {
+				return nil, fmt.Errorf("invalid %s entry: missing registry", RegistryAuthHeader)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s entry for %s: %w", RegistryAuthHeader, registry, err)
+			}
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid %s entry for %s: expected username:password", RegistryAuthHeader, registry)
+			}
+			if registry == "docker.io" || registry == "index.docker.io" {
+				registry = dockerHubHost
+			}
+			creds[registry] = auth.Credential{
+				Username: username,
+				Password: password,
+			}
+		}
+	}
+	return func(_ context.Context, hostport string) (auth.Credential, error) {
+		return creds[hostport], nil
+	}, nil
+}
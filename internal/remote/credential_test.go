@@ -0,0 +1,140 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestCredentialFromHeader(t *testing.T) {
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	header := http.Header{}
+	header.Add(RegistryAuthHeader, "ghcr.io="+encode("alice:secret")+", localhost:5000="+encode("bob:pa:ss"))
+	header.Add(RegistryAuthHeader, "example.com="+encode(":token"))
+
+	credential, err := CredentialFromHeader(header)
+	if err != nil {
+		t.Fatalf("CredentialFromHeader() error = %v", err)
+	}
+
+	tests := []struct {
+		hostport string
+		want     auth.Credential
+	}{
+		{
+			hostport: "ghcr.io",
+			want:     auth.Credential{Username: "alice", Password: "secret"},
+		},
+		{
+			hostport: "localhost:5000",
+			want:     auth.Credential{Username: "bob", Password: "pa:ss"},
+		},
+		{
+			hostport: "example.com",
+			want:     auth.Credential{Password: "token"},
+		},
+		{
+			hostport: "unknown.io",
+			want:     auth.EmptyCredential,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hostport, func(t *testing.T) {
+			got, err := credential(context.Background(), tt.hostport)
+			if err != nil {
+				t.Fatalf("credential() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("credential() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialFromHeader_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{
+			name:  "missing registry",
+			value: "=" + base64.StdEncoding.EncodeToString([]byte("user:pass")),
+		},
+		{
+			name:  "missing separator",
+			value: "ghcr.io",
+		},
+		{
+			name:  "invalid base64",
+			value: "ghcr.io=!!!",
+		},
+		{
+			name:  "missing password separator",
+			value: "ghcr.io=" + base64.StdEncoding.EncodeToString([]byte("user")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(RegistryAuthHeader, tt.value)
+			if _, err := CredentialFromHeader(header); err == nil {
+				t.Fatalf("CredentialFromHeader() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCredentialFromHeader_Empty(t *testing.T) {
+	credential, err := CredentialFromHeader(http.Header{})
+	if err != nil {
+		t.Fatalf("CredentialFromHeader() error = %v", err)
+	}
+	got, err := credential(context.Background(), "ghcr.io")
+	if err != nil {
+		t.Fatalf("credential() error = %v", err)
+	}
+	if got != auth.EmptyCredential {
+		t.Errorf("credential() = %+v, want empty credential", got)
+	}
+}
+
+func TestCredentialFromHeader_DockerHub(t *testing.T) {
+	for _, registry := range []string{"docker.io", "index.docker.io", "registry-1.docker.io"} {
+		t.Run(registry, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(RegistryAuthHeader, registry+"="+base64.StdEncoding.EncodeToString([]byte("alice:secret")))
+			credential, err := CredentialFromHeader(header)
+			if err != nil {
+				t.Fatalf("CredentialFromHeader() error = %v", err)
+			}
+			// oras-go requests the credentials of Docker Hub by its API host
+			got, err := credential(context.Background(), "registry-1.docker.io")
+			if err != nil {
+				t.Fatalf("credential() error = %v", err)
+			}
+			if want := (auth.Credential{Username: "alice", Password: "secret"}); got != want {
+				t.Errorf("credential() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
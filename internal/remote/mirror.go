@@ -0,0 +1,60 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"strings"
+
+	"github.com/oras-project/oras-mcp/internal/config"
+	"oras.land/oras-go/v2/registry"
+)
+
+// endpoints returns the references of the endpoints serving ref in the order
+// they are tried: the mirrors of the longest matching mirror rule followed by
+// the upstream, which is rewritten by the location of the rule.
+func endpoints(ref registry.Reference) []registry.Reference {
+	name := ref.Registry + "/" + ref.Repository
+	var rule *config.RegistryMirror
+	for i, mirror := range registryConfig.Mirrors {
+		if name != mirror.Prefix && !strings.HasPrefix(name, mirror.Prefix+"/") {
+			continue
+		}
+		if rule == nil || len(mirror.Prefix) > len(rule.Prefix) {
+			rule = &registryConfig.Mirrors[i]
+		}
+	}
+	if rule == nil {
+		return []registry.Reference{ref}
+	}
+
+	suffix := strings.TrimPrefix(name, rule.Prefix)
+	upstream := rule.Location
+	if upstream == "" {
+		upstream = rule.Prefix
+	}
+	refs := make([]registry.Reference, 0, len(rule.Mirrors)+1)
+	for _, location := range append(rule.Mirrors, upstream) {
+		// locations are validated by the configuration validation to include
+		// a repository if the prefix does
+		host, repository, _ := strings.Cut(location+suffix, "/")
+		refs = append(refs, registry.Reference{
+			Registry:   host,
+			Repository: repository,
+			Reference:  ref.Reference,
+		})
+	}
+	return refs
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oras-project/oras-mcp/internal/config"
+	"oras.land/oras-go/v2/registry"
+)
+
+// TestEndpoints tests the endpoints resolved by the mirror rules.
+func TestEndpoints(t *testing.T) {
+	t.Cleanup(func() {
+		Configure(config.Default().Registry)
+	})
+	Configure(config.Registry{
+		Mirrors: []config.RegistryMirror{
+			{
+				Prefix:  "docker.io",
+				Mirrors: []string{"mirror.corp/dockerhub", "mirror.backup"},
+			},
+			{
+				Prefix:   "docker.io/library/alpine",
+				Location: "registry.corp/alpine",
+			},
+			{
+				Prefix:   "quay.io/team",
+				Location: "registry.corp/quay/team",
+				Mirrors:  []string{"mirror.corp/quay/team"},
+			},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		reference string
+		want      []string
+	}{
+		{
+			name:      "no matching rule",
+			reference: "ghcr.io/oras-project/oras:v1",
+			want:      []string{"ghcr.io/oras-project/oras:v1"},
+		},
+		{
+			name:      "registry mirrors",
+			reference: "docker.io/library/ubuntu:24.04",
+			want: []string{
+				"mirror.corp/dockerhub/library/ubuntu:24.04",
+				"mirror.backup/library/ubuntu:24.04",
+				"docker.io/library/ubuntu:24.04",
+			},
+		},
+		{
+			name:      "longest prefix wins",
+			reference: "docker.io/library/alpine:3",
+			want:      []string{"registry.corp/alpine:3"},
+		},
+		{
+			name:      "prefix matches on path boundaries",
+			reference: "docker.io/library/alpinelinux:3",
+			want: []string{
+				"mirror.corp/dockerhub/library/alpinelinux:3",
+				"mirror.backup/library/alpinelinux:3",
+				"docker.io/library/alpinelinux:3",
+			},
+		},
+		{
+			name:      "rewritten upstream with mirrors",
+			reference: "quay.io/team/app@sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7",
+			want: []string{
+				"mirror.corp/quay/team/app@sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7",
+				"registry.corp/quay/team/app@sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7",
+			},
+		},
+		{
+			name:      "other namespace of the registry",
+			reference: "quay.io/other/app:v1",
+			want:      []string{"quay.io/other/app:v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := registry.ParseReference(tt.reference)
+			if err != nil {
+				t.Fatalf("Failed to parse reference: %v", err)
+			}
+			var got []string
+			for _, endpoint := range endpoints(ref) {
+				got = append(got, endpoint.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("endpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
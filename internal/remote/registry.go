@@ -15,16 +15,21 @@ limitations under the License.
 
 package remote
 
-import "oras.land/oras-go/v2/registry/remote"
+import (
+	"context"
 
-// NewRegistry assembles an oras-mcp remote registry client.
-func NewRegistry(name string) (*remote.Registry, error) {
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// NewRegistry assembles an oras-mcp remote registry client, authenticated by
+// the auth client carried by ctx.
+func NewRegistry(ctx context.Context, name string) (*remote.Registry, error) {
 	reg, err := remote.NewRegistry(name)
 	if err != nil {
 		return nil, err
 	}
 
-	reg.Client = DefaultClient
+	reg.Client = ClientFromContext(ctx)
 	reg.PlainHTTP = isPlainHttp(name)
 
 	return reg, nil
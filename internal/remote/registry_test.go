@@ -15,7 +15,10 @@ limitations under the License.
 
 package remote
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestNewRegistry(t *testing.T) {
 	tests := []struct {
@@ -37,7 +40,7 @@ func TestNewRegistry(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			reg, err := NewRegistry(tt.registry)
+			reg, err := NewRegistry(context.Background(), tt.registry)
 			if err != nil {
 				t.Fatalf("NewRegistry() error = %v", err)
 			}
@@ -58,7 +61,20 @@ func TestNewRegistry(t *testing.T) {
 }
 
 func TestNewRegistryInvalid(t *testing.T) {
-	if _, err := NewRegistry("https://example.com"); err == nil {
+	if _, err := NewRegistry(context.Background(), "https://example.com"); err == nil {
 		t.Fatal("expected error for registry with scheme, got nil")
 	}
 }
+
+func TestNewRegistryClientFromContext(t *testing.T) {
+	client := NewClient(nil)
+	ctx := WithClient(context.Background(), client)
+
+	reg, err := NewRegistry(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	if reg.Client != client {
+		t.Errorf("Client = %v, want client from context", reg.Client)
+	}
+}
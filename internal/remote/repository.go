@@ -16,16 +16,298 @@ limitations under the License.
 package remote
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
-// NewRepository assembles an oras-mcp remote repository.
-func NewRepository(ref registry.Reference) *remote.Repository {
-	return &remote.Repository{
-		Client:          DefaultClient,
-		Reference:       ref,
-		PlainHTTP:       isPlainHttp(ref.Registry),
-		SkipReferrersGC: true,
+// Repository is a remote repository served by the configured mirrors with
+// fallback to the upstream. It is not safe for concurrent use.
+type Repository struct {
+	// Reference is the requested reference of the repository.
+	Reference registry.Reference
+	// Refresh resolves tags against the registry instead of the tag cache,
+	// updating the cache with the results.
+	Refresh bool
+	// TagListPageSize specifies the page size when listing tags. The registry
+	// default is used if zero.
+	TagListPageSize int
+
+	client    *auth.Client
+	endpoints []*remote.Repository
+	served    *remote.Repository
+}
+
+// NewRepository assembles an oras-mcp remote repository, authenticated by the
+// auth client carried by ctx.
+func NewRepository(ctx context.Context, ref registry.Reference) *Repository {
+	client := ClientFromContext(ctx)
+	repo := &Repository{
+		Reference: ref,
+		client:    client,
+	}
+	for _, endpoint := range endpoints(ref) {
+		repo.endpoints = append(repo.endpoints, &remote.Repository{
+			Client:          client,
+			Reference:       endpoint,
+			PlainHTTP:       isPlainHttp(endpoint.Registry),
+			SkipReferrersGC: true,
+		})
+	}
+	return repo
+}
+
+// Endpoint returns the reference of the endpoint that served the last
+// successful request, or the requested reference if there is none.
+func (r *Repository) Endpoint() registry.Reference {
+	if r.served == nil {
+		return r.Reference
+	}
+	return r.served.Reference
+}
+
+// Mirrored reports whether the last successful request was served by an
+// endpoint other than the requested repository.
+func (r *Repository) Mirrored() bool {
+	endpoint := r.Endpoint()
+	return endpoint.Registry != r.Reference.Registry || endpoint.Repository != r.Reference.Repository
+}
+
+// Fetch fetches the content identified by the descriptor, consulting the
+// content cache first.
+func (r *Repository) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io.ReadCloser, err error) {
+	if _, rc, ok := fetchCached(ctx, target.Digest.String()); ok {
+		if err := r.confirmAccess(ctx, target, false); err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return rc, nil
+	}
+	err = r.try(ctx, func(repo *remote.Repository) error {
+		rc, err = repo.Fetch(ctx, target)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cacheContent(ctx, target, rc), nil
+}
+
+// FetchReference fetches the manifest identified by the reference, consulting
+// the tag cache first if the reference is a tag, and the content cache if it
+// is a digest.
+func (r *Repository) FetchReference(ctx context.Context, reference string) (desc ocispec.Descriptor, rc io.ReadCloser, err error) {
+	if desc, ok := r.cachedTag(reference); ok {
+		return r.FetchReference(ctx, desc.Digest.String())
+	}
+	if _, rc, ok := fetchCached(ctx, reference); ok {
+		// the content may have been cached as a blob, so the manifest is
+		// resolved by the registry, confirming access to it as well
+		desc, err := r.Resolve(ctx, reference)
+		if err != nil {
+			rc.Close()
+			return ocispec.Descriptor{}, nil, err
+		}
+		return desc, rc, nil
+	}
+	err = r.try(ctx, func(repo *remote.Repository) error {
+		desc, rc, err = repo.FetchReference(ctx, reference)
+		return err
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	r.cacheTag(reference, desc)
+	return desc, cacheContent(ctx, desc, rc), nil
+}
+
+// Resolve resolves the reference to the descriptor of the manifest, consulting
+// the tag cache first if the reference is a tag. The content cache is not
+// consulted as confirming access to the content costs as much as resolving it.
+func (r *Repository) Resolve(ctx context.Context, reference string) (desc ocispec.Descriptor, err error) {
+	if desc, ok := r.cachedTag(reference); ok {
+		return desc, nil
+	}
+	err = r.try(ctx, func(repo *remote.Repository) error {
+		desc, err = repo.Resolve(ctx, reference)
+		return err
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	r.cacheTag(reference, desc)
+	return desc, nil
+}
+
+// tagKey returns the tag cache key of the reference, reporting false if the
+// reference is a digest.
+func (r *Repository) tagKey(reference string) (tagKey, bool) {
+	if _, err := digest.Parse(reference); err == nil {
+		return tagKey{}, false
+	}
+	ref := r.Reference
+	ref.Reference = reference
+	return tagKey{client: r.client, reference: ref.String()}, true
+}
+
+// cachedTag returns the descriptor cached for the tag unless refreshing.
+func (r *Repository) cachedTag(reference string) (ocispec.Descriptor, bool) {
+	key, ok := r.tagKey(reference)
+	if !ok || r.Refresh {
+		return ocispec.Descriptor{}, false
 	}
+	return tags.get(key)
+}
+
+// cacheTag caches the descriptor resolved from the tag.
+func (r *Repository) cacheTag(reference string, desc ocispec.Descriptor) {
+	if key, ok := r.tagKey(reference); ok {
+		tags.set(key, desc)
+	}
+}
+
+// Blobs returns the blob store of the repository.
+func (r *Repository) Blobs() *BlobStore {
+	return &BlobStore{repo: r}
+}
+
+// Referrers lists the descriptors of the manifests directly referencing the
+// given manifest descriptor. Endpoints are only fallen back on before the first
+// page of referrers is delivered to fn.
+func (r *Repository) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	return r.tryPages(ctx, func(repo *remote.Repository, delivered *bool) error {
+		return repo.Referrers(ctx, desc, artifactType, func(referrers []ocispec.Descriptor) error {
+			*delivered = true
+			return fn(referrers)
+		})
+	})
+}
+
+// Tags lists the tags of the repository. Endpoints are only fallen back on
+// before the first page of tags is delivered to fn.
+func (r *Repository) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	return r.tryPages(ctx, func(repo *remote.Repository, delivered *bool) error {
+		repo.TagListPageSize = r.TagListPageSize
+		return repo.Tags(ctx, last, func(tags []string) error {
+			*delivered = true
+			return fn(tags)
+		})
+	})
+}
+
+// confirmAccess confirms that the content described by desc exists in the
+// repository and is accessible with the credentials of the repository before
+// serving it from the content cache, which is shared by all sessions.
+func (r *Repository) confirmAccess(ctx context.Context, desc ocispec.Descriptor, blob bool) error {
+	return r.try(ctx, func(repo *remote.Repository) error {
+		var exists bool
+		var err error
+		if blob {
+			exists, err = repo.Blobs().Exists(ctx, desc)
+		} else {
+			exists, err = repo.Exists(ctx, desc)
+		}
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", desc.Digest, errdef.ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// try calls fn with the endpoints in order until it succeeds.
+func (r *Repository) try(ctx context.Context, fn func(repo *remote.Repository) error) error {
+	return r.tryPages(ctx, func(repo *remote.Repository, _ *bool) error {
+		return fn(repo)
+	})
+}
+
+// tryPages calls fn with the endpoints in order until it succeeds, the context
+// is done, or fn reports that part of the result has been delivered.
+func (r *Repository) tryPages(ctx context.Context, fn func(repo *remote.Repository, delivered *bool) error) error {
+	var errs []error
+	for i, endpoint := range r.endpoints {
+		var delivered bool
+		err := fn(endpoint, &delivered)
+		if err == nil {
+			r.served = endpoint
+			return nil
+		}
+		if delivered || ctx.Err() != nil {
+			return err
+		}
+		if i < len(r.endpoints)-1 {
+			err = fmt.Errorf("mirror %s/%s: %w", endpoint.Reference.Registry, endpoint.Reference.Repository, err)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// BlobStore is the blob store of a repository served by the configured
+// mirrors with fallback to the upstream.
+type BlobStore struct {
+	repo *Repository
+}
+
+// Fetch fetches the blob identified by the descriptor, consulting the content
+// cache first.
+func (s *BlobStore) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io.ReadCloser, err error) {
+	if _, rc, ok := fetchCached(ctx, target.Digest.String()); ok {
+		if err := s.repo.confirmAccess(ctx, target, true); err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return rc, nil
+	}
+	err = s.repo.try(ctx, func(repo *remote.Repository) error {
+		rc, err = repo.Blobs().Fetch(ctx, target)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cacheContent(ctx, target, rc), nil
+}
+
+// FetchReference fetches the blob identified by the digest reference,
+// consulting the content cache first.
+func (s *BlobStore) FetchReference(ctx context.Context, reference string) (desc ocispec.Descriptor, rc io.ReadCloser, err error) {
+	if desc, rc, ok := fetchCached(ctx, reference); ok {
+		if err := s.repo.confirmAccess(ctx, desc, true); err != nil {
+			rc.Close()
+			return ocispec.Descriptor{}, nil, err
+		}
+		return desc, rc, nil
+	}
+	err = s.repo.try(ctx, func(repo *remote.Repository) error {
+		desc, rc, err = repo.Blobs().FetchReference(ctx, reference)
+		return err
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, cacheContent(ctx, desc, rc), nil
+}
+
+// Resolve resolves the digest reference to the descriptor of the blob.
+func (s *BlobStore) Resolve(ctx context.Context, reference string) (desc ocispec.Descriptor, err error) {
+	err = s.repo.try(ctx, func(repo *remote.Repository) error {
+		desc, err = repo.Blobs().Resolve(ctx, reference)
+		return err
+	})
+	return desc, err
 }
@@ -16,12 +16,18 @@ limitations under the License.
 package remote
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/oras-project/oras-mcp/internal/config"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 )
@@ -54,8 +60,8 @@ func TestNewRepository(t *testing.T) {
 			name:      "valid reference",
 			reference: host + ":" + port + "/test-repo",
 			checkFields: func(t *testing.T, repo *remote.Repository) {
-				if repo.PlainHTTP { // PlainHTTP should be false for non-localhost hosts, even if using HTTP
-					t.Errorf("Expected PlainHTTP to be false for non-localhost host")
+				if !repo.PlainHTTP { // the test server listens on a loopback address
+					t.Errorf("Expected PlainHTTP to be true for loopback address")
 				}
 				if repo.Client != DefaultClient {
 					t.Errorf("Expected Client to be DefaultClient")
@@ -71,6 +77,15 @@ func TestNewRepository(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:      "remote reference",
+			reference: "example.com:5000/test-repo",
+			checkFields: func(t *testing.T, repo *remote.Repository) {
+				if repo.PlainHTTP {
+					t.Errorf("Expected PlainHTTP to be false for non-loopback host")
+				}
+			},
+		},
 		{
 			name:      "localhost reference",
 			reference: "localhost:5000/test-repo",
@@ -91,12 +106,134 @@ func TestNewRepository(t *testing.T) {
 			}
 
 			// Create repository with parsed reference
-			repo := NewRepository(ref)
+			repo := NewRepository(context.Background(), ref)
 			if repo == nil {
 				t.Fatal("NewRepository() returned nil repository")
 			}
 
-			tt.checkFields(t, repo)
+			if len(repo.endpoints) != 1 {
+				t.Fatalf("Expected 1 endpoint, got %d", len(repo.endpoints))
+			}
+			tt.checkFields(t, repo.endpoints[0])
 		})
 	}
 }
+
+// TestNewRepositoryClientFromContext tests that the repository is
+// authenticated by the client carried by the context.
+func TestNewRepositoryClientFromContext(t *testing.T) {
+	client := NewClient(nil)
+	ctx := WithClient(context.Background(), client)
+
+	ref, err := registry.ParseReference("example.com/test-repo")
+	if err != nil {
+		t.Fatalf("Failed to parse reference: %v", err)
+	}
+	repo := NewRepository(ctx, ref)
+	if repo.endpoints[0].Client != client {
+		t.Errorf("Expected Client to be the client from context")
+	}
+}
+
+// TestRepository_Fallback tests that the mirrors are tried in order with
+// fallback to the upstream.
+func TestRepository_Fallback(t *testing.T) {
+	const manifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`
+	newServer := func(name string, tags []string, hasManifest bool) string {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/v2/"+name+"/tags/list" && tags != nil:
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, `{"tags":["`+strings.Join(tags, `","`)+`"]}`)
+			case r.URL.Path == "/v2/"+name+"/manifests/v1" && hasManifest:
+				w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+				io.WriteString(w, manifest)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(ts.Close)
+		return strings.Replace(strings.TrimPrefix(ts.URL, "http://"), "127.0.0.1", "localhost", 1)
+	}
+	mirror := newServer("mirror/test-repo", []string{"cached"}, false)
+	upstream := newServer("test-repo", []string{"v1", "v2"}, true)
+	empty := newServer("none", nil, false)
+
+	t.Cleanup(func() {
+		Configure(config.Default().Registry)
+	})
+	Configure(config.Registry{
+		PlainHTTP: []string{"localhost"},
+		Mirrors: []config.RegistryMirror{
+			{
+				Prefix:  upstream,
+				Mirrors: []string{empty + "/none", mirror + "/mirror"},
+			},
+		},
+	})
+	ctx := context.Background()
+	ref := registry.Reference{
+		Registry:   upstream,
+		Repository: "test-repo",
+		Reference:  "v1",
+	}
+
+	t.Run("served by mirror", func(t *testing.T) {
+		repo := NewRepository(ctx, ref)
+		if repo.Mirrored() {
+			t.Errorf("Expected no endpoint before requests")
+		}
+		tags, err := registry.Tags(ctx, repo)
+		if err != nil {
+			t.Fatalf("Tags() error = %v", err)
+		}
+		if len(tags) != 1 || tags[0] != "cached" {
+			t.Errorf("Tags() = %v, want [cached]", tags)
+		}
+		if !repo.Mirrored() {
+			t.Errorf("Expected the content to be mirrored")
+		}
+		if got := repo.Endpoint(); got.Registry != mirror || got.Repository != "mirror/test-repo" {
+			t.Errorf("Endpoint() = %v, want %s/mirror/test-repo", got, mirror)
+		}
+	})
+
+	t.Run("fall back to upstream", func(t *testing.T) {
+		repo := NewRepository(ctx, ref)
+		_, rc, err := repo.FetchReference(ctx, ref.Reference)
+		if err != nil {
+			t.Fatalf("FetchReference() error = %v", err)
+		}
+		defer rc.Close()
+		got, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		if string(got) != manifest {
+			t.Errorf("FetchReference() = %s, want %s", got, manifest)
+		}
+		if repo.Mirrored() {
+			t.Errorf("Expected the content to be served by the upstream, got %v", repo.Endpoint())
+		}
+	})
+
+	t.Run("all endpoints fail", func(t *testing.T) {
+		repo := NewRepository(ctx, ref)
+		_, err := repo.Resolve(ctx, "missing")
+		if !errors.Is(err, errdef.ErrNotFound) {
+			t.Fatalf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+		}
+		if !strings.Contains(err.Error(), "mirror "+empty+"/none/test-repo") {
+			t.Errorf("Expected the error to report the failed mirrors, got %v", err)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		repo := NewRepository(ctx, ref)
+		if _, err := repo.Resolve(ctx, ref.Reference); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Resolve() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// tags is the tag cache shared by all repositories.
+var tags = newTagCache(registryConfig.TagCacheTTL)
+
+// tagCache is an in-memory cache of the descriptors resolved from tags. The
+// entries are keyed by the auth client as well so that sessions with different
+// credentials do not observe the tags resolved by each other.
+type tagCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[tagKey]tagEntry
+}
+
+// tagKey is the key of a tag cache entry.
+type tagKey struct {
+	client    *auth.Client
+	reference string
+}
+
+// tagEntry is a tag cache entry.
+type tagEntry struct {
+	desc    ocispec.Descriptor
+	expires time.Time
+}
+
+// newTagCache creates a tag cache keeping the entries for ttl. A non-positive
+// ttl disables the cache.
+func newTagCache(ttl time.Duration) *tagCache {
+	return &tagCache{
+		ttl:     ttl,
+		entries: make(map[tagKey]tagEntry),
+	}
+}
+
+// get returns the unexpired descriptor cached for the key.
+func (c *tagCache) get(key tagKey) (ocispec.Descriptor, bool) {
+	if c.ttl <= 0 {
+		return ocispec.Descriptor{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return ocispec.Descriptor{}, false
+	}
+	return entry.desc, true
+}
+
+// set caches the descriptor for the key, dropping the expired entries.
+func (c *tagCache) set(key tagKey, desc ocispec.Descriptor) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = tagEntry{
+		desc:    desc,
+		expires: now.Add(c.ttl),
+	}
+}
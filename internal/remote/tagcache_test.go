@@ -0,0 +1,140 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/config"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestTagCache(t *testing.T) {
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("{}"))
+	key := tagKey{client: DefaultClient, reference: "example.com/test-repo:v1"}
+
+	t.Run("cached until expired", func(t *testing.T) {
+		c := newTagCache(time.Minute)
+		if _, ok := c.get(key); ok {
+			t.Fatalf("Expected no entry before set")
+		}
+		c.set(key, desc)
+		if got, ok := c.get(key); !ok || !content.Equal(got, desc) {
+			t.Fatalf("get() = %v, %v, want %v, true", got, ok, desc)
+		}
+		if _, ok := c.get(tagKey{client: NewClient(nil), reference: key.reference}); ok {
+			t.Errorf("Expected entries to be keyed by the client")
+		}
+
+		c.entries[key] = tagEntry{desc: desc, expires: time.Now().Add(-time.Second)}
+		if _, ok := c.get(key); ok {
+			t.Errorf("Expected the expired entry to be ignored")
+		}
+		other := tagKey{client: DefaultClient, reference: "example.com/test-repo:v2"}
+		c.set(other, desc)
+		if _, ok := c.entries[key]; ok {
+			t.Errorf("Expected the expired entry to be dropped")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c := newTagCache(0)
+		c.set(key, desc)
+		if _, ok := c.get(key); ok {
+			t.Errorf("Expected the disabled cache to be empty")
+		}
+	})
+}
+
+// TestRepository_TagCache tests that tags are resolved through the tag cache.
+func TestRepository_TagCache(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	var requests atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/v2/test-repo/manifests/v1", "/v2/test-repo/manifests/" + manifestDesc.Digest.String():
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+			w.Write(manifest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	t.Cleanup(func() {
+		Configure(config.Default().Registry)
+	})
+	cfg := config.Default().Registry
+	cfg.TagCacheTTL = time.Minute
+	Configure(cfg)
+
+	ctx := context.Background()
+	ref := registry.Reference{
+		Registry:   strings.TrimPrefix(ts.URL, "http://"),
+		Repository: "test-repo",
+	}
+	resolve := func(ctx context.Context, refresh bool) {
+		t.Helper()
+		repo := NewRepository(ctx, ref)
+		repo.Refresh = refresh
+		desc, err := repo.Resolve(ctx, "v1")
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if !content.Equal(desc, manifestDesc) {
+			t.Fatalf("Resolve() = %v, want %v", desc, manifestDesc)
+		}
+	}
+
+	resolve(ctx, false)
+	resolve(ctx, false)
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	resolve(ctx, true)
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	resolve(WithClient(ctx, NewClient(nil)), false)
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+
+	// the manifest of a cached tag is fetched by digest
+	repo := NewRepository(ctx, ref)
+	desc, rc, err := repo.FetchReference(ctx, "v1")
+	if err != nil {
+		t.Fatalf("FetchReference() error = %v", err)
+	}
+	rc.Close()
+	if !content.Equal(desc, manifestDesc) {
+		t.Errorf("FetchReference() = %v, want %v", desc, manifestDesc)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/oras-project/oras-mcp/internal/config"
+)
+
+// hostTransport is an http.RoundTripper sending requests through the transport
+// with the TLS settings configured for their hosts.
+type hostTransport struct {
+	base *http.Transport
+	cfg  config.Registry
+
+	lock       sync.Mutex
+	transports map[string]*http.Transport // keyed by host:port
+}
+
+// newTransport creates the base transport of auth clients for the registry
+// configuration.
+func newTransport(cfg config.Registry) *hostTransport {
+	return &hostTransport{
+		base:       http.DefaultTransport.(*http.Transport).Clone(),
+		cfg:        cfg,
+		transports: make(map[string]*http.Transport),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, err := t.transport(req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// transport returns the transport for the host, creating it on first use.
+func (t *hostTransport) transport(host string) (*http.Transport, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if transport, ok := t.transports[host]; ok {
+		return transport, nil
+	}
+
+	tlsConfig, err := t.tlsConfig(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS configuration for %s: %w", host, err)
+	}
+	transport := t.base
+	if tlsConfig != nil {
+		transport = t.base.Clone()
+		transport.TLSClientConfig = tlsConfig
+	}
+	t.transports[host] = transport
+	return transport, nil
+}
+
+// tlsConfig assembles the TLS configuration for the host. It returns nil if
+// the host has no TLS settings configured.
+func (t *hostTransport) tlsConfig(host string) (*tls.Config, error) {
+	var caFiles []string
+	var keyPairs []config.RegistryTLS
+	for _, dir := range t.cfg.CertsDirs {
+		ca, pairs, err := readCertsDir(filepath.Join(dir, host))
+		if err != nil {
+			return nil, err
+		}
+		caFiles = append(caFiles, ca...)
+		keyPairs = append(keyPairs, pairs...)
+	}
+	for _, entry := range t.cfg.TLS {
+		if !matchRegistry([]string{entry.Registry}, host) {
+			continue
+		}
+		if entry.CAFile != "" {
+			caFiles = append(caFiles, entry.CAFile)
+		}
+		if entry.CertFile != "" {
+			keyPairs = append(keyPairs, entry)
+		}
+	}
+	skipTLSVerify := matchRegistry(t.cfg.SkipTLSVerify, host)
+	if !skipTLSVerify && len(caFiles) == 0 && len(keyPairs) == 0 {
+		return nil, nil
+	}
+
+	var tlsConfig *tls.Config
+	if t.base.TLSClientConfig != nil {
+		tlsConfig = t.base.TLSClientConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.InsecureSkipVerify = skipTLSVerify
+	if len(caFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, caFile := range caFiles {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", caFile)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	for _, pair := range keyPairs {
+		cert, err := tls.LoadX509KeyPair(pair.CertFile, pair.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	return tlsConfig, nil
+}
+
+// readCertsDir lists the CA bundles and client certificate pairs in a
+// directory of the Docker certs.d layout. A missing directory has no
+// certificates.
+func readCertsDir(dir string) (caFiles []string, keyPairs []config.RegistryTLS, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		switch filepath.Ext(name) {
+		case ".crt":
+			caFiles = append(caFiles, filepath.Join(dir, name))
+		case ".cert":
+			keyName := strings.TrimSuffix(name, ".cert") + ".key"
+			keyFile := filepath.Join(dir, keyName)
+			if _, err := os.Stat(keyFile); err != nil {
+				return nil, nil, fmt.Errorf("missing key %s for client certificate %s", keyName, name)
+			}
+			keyPairs = append(keyPairs, config.RegistryTLS{
+				CertFile: filepath.Join(dir, name),
+				KeyFile:  keyFile,
+			})
+		}
+	}
+	return caFiles, keyPairs, nil
+}
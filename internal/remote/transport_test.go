@@ -0,0 +1,324 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oras-project/oras-mcp/internal/config"
+)
+
+// testCertificate is a certificate with its private key for testing.
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCertificate issues a certificate signed by parent, or a self-signed
+// CA certificate if parent is nil.
+func newTestCertificate(t *testing.T, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "oras-mcp-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return &testCertificate{cert: cert, key: key, der: der}
+}
+
+// writeCert writes the certificate in PEM format to path.
+func (c *testCertificate) writeCert(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+}
+
+// writeKey writes the private key in PEM format to path.
+func (c *testCertificate) writeKey(t *testing.T, path string) {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+// newTLSServer starts a TLS server with a certificate issued by ca, requiring
+// client certificates issued by ca if mutual is set.
+func newTLSServer(t *testing.T, ca *testCertificate, mutual bool) (*httptest.Server, string) {
+	t.Helper()
+	serverCert := newTestCertificate(t, ca)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.der}, PrivateKey: serverCert.key}},
+	}
+	if mutual {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		ts.TLS.ClientCAs = pool
+		ts.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts, strings.TrimPrefix(ts.URL, "https://")
+}
+
+// roundTrip sends a GET request to url through the transport.
+func roundTrip(transport http.RoundTripper, url string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// TestHostTransport_SkipTLSVerify tests that TLS verification is skipped only
+// for the configured registries.
+func TestHostTransport_SkipTLSVerify(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "https://")
+
+	tests := []struct {
+		name          string
+		skipTLSVerify []string
+		wantErr       bool
+	}{
+		{
+			name:    "verify TLS by default",
+			wantErr: true,
+		},
+		{
+			name:          "skip TLS verification for the registry",
+			skipTLSVerify: []string{host},
+		},
+		{
+			name:          "skip TLS verification for the IP range",
+			skipTLSVerify: []string{"127.0.0.0/8"},
+		},
+		{
+			name:          "verify TLS of other registries",
+			skipTLSVerify: []string{"registry.insecure"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := newTransport(config.Registry{SkipTLSVerify: tt.skipTLSVerify})
+			if err := roundTrip(transport, ts.URL); (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestHostTransport_TLSEntry tests the CA bundles and client certificates
+// configured for individual registries.
+func TestHostTransport_TLSEntry(t *testing.T) {
+	tempDir := t.TempDir()
+	ca := newTestCertificate(t, nil)
+	caFile := filepath.Join(tempDir, "ca.pem")
+	ca.writeCert(t, caFile)
+	client := newTestCertificate(t, ca)
+	certFile := filepath.Join(tempDir, "client.pem")
+	keyFile := filepath.Join(tempDir, "client-key.pem")
+	client.writeCert(t, certFile)
+	client.writeKey(t, keyFile)
+	ts, host := newTLSServer(t, ca, true)
+
+	tests := []struct {
+		name    string
+		entries []config.RegistryTLS
+		wantErr bool
+	}{
+		{
+			name:    "no certificates",
+			wantErr: true,
+		},
+		{
+			name: "CA bundle without client certificate",
+			entries: []config.RegistryTLS{
+				{Registry: host, CAFile: caFile},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CA bundle and client certificate",
+			entries: []config.RegistryTLS{
+				{Registry: host, CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+			},
+		},
+		{
+			name: "host entry matches any port",
+			entries: []config.RegistryTLS{
+				{Registry: "127.0.0.1", CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+			},
+		},
+		{
+			name: "entry of another registry",
+			entries: []config.RegistryTLS{
+				{Registry: "registry.corp", CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := newTransport(config.Registry{TLS: tt.entries})
+			if err := roundTrip(transport, ts.URL); (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestHostTransport_CertsDir tests the certificates in the Docker certs.d
+// layout.
+func TestHostTransport_CertsDir(t *testing.T) {
+	ca := newTestCertificate(t, nil)
+	client := newTestCertificate(t, ca)
+	ts, host := newTLSServer(t, ca, true)
+
+	certsDir := t.TempDir()
+	hostDir := filepath.Join(certsDir, host)
+	if err := os.MkdirAll(hostDir, 0o700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	ca.writeCert(t, filepath.Join(hostDir, "ca.crt"))
+	client.writeCert(t, filepath.Join(hostDir, "client.cert"))
+	client.writeKey(t, filepath.Join(hostDir, "client.key"))
+
+	transport := newTransport(config.Registry{
+		CertsDirs: []string{filepath.Join(t.TempDir(), "missing"), certsDir},
+	})
+	if err := roundTrip(transport, ts.URL); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+}
+
+// TestHostTransport_InvalidCertificates tests that invalid certificates fail
+// the requests to the affected registries.
+func TestHostTransport_InvalidCertificates(t *testing.T) {
+	tempDir := t.TempDir()
+	invalidFile := filepath.Join(tempDir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	certsDir := filepath.Join(tempDir, "certs.d")
+	if err := os.MkdirAll(filepath.Join(certsDir, "registry.nokey"), 0o700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	newTestCertificate(t, nil).writeCert(t, filepath.Join(certsDir, "registry.nokey", "client.cert"))
+
+	transport := newTransport(config.Registry{
+		CertsDirs: []string{certsDir},
+		TLS: []config.RegistryTLS{
+			{Registry: "registry.badca", CAFile: invalidFile},
+			{Registry: "registry.missingca", CAFile: filepath.Join(tempDir, "missing.pem")},
+			{Registry: "registry.badpair", CertFile: invalidFile, KeyFile: invalidFile},
+		},
+	})
+	for _, host := range []string{"registry.badca", "registry.missingca", "registry.badpair", "registry.nokey"} {
+		t.Run(host, func(t *testing.T) {
+			if _, err := transport.transport(host); err == nil {
+				t.Fatalf("transport() error = nil, want error")
+			}
+			if err := roundTrip(transport, "https://"+host); err == nil {
+				t.Fatalf("RoundTrip() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestHostTransport_Reuse tests that transports are created once per host.
+func TestHostTransport_Reuse(t *testing.T) {
+	transport := newTransport(config.Registry{SkipTLSVerify: []string{"registry.insecure"}})
+
+	got, err := transport.transport("example.com")
+	if err != nil {
+		t.Fatalf("transport() error = %v", err)
+	}
+	if got != transport.base {
+		t.Errorf("Expected the base transport for other hosts")
+	}
+	insecure, err := transport.transport("registry.insecure")
+	if err != nil {
+		t.Fatalf("transport() error = %v", err)
+	}
+	if insecure == transport.base {
+		t.Fatalf("Expected a dedicated transport for the insecure registry")
+	}
+	if !insecure.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("Expected TLS verification to be skipped")
+	}
+	if transport.base.TLSClientConfig != nil && transport.base.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("Expected TLS verification of the base transport to be kept")
+	}
+	if got, _ := transport.transport("registry.insecure"); got != insecure {
+		t.Errorf("Expected the transport to be reused")
+	}
+}
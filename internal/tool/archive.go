@@ -0,0 +1,156 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// Whiteout file names of the OCI layer format.
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// Types of the entries in a layer.
+const (
+	entryFile           = "file"
+	entryDir            = "dir"
+	entrySymlink        = "symlink"
+	entryHardlink       = "hardlink"
+	entryChar           = "char"
+	entryBlock          = "block"
+	entryFifo           = "fifo"
+	entryOther          = "other"
+	entryWhiteout       = "whiteout"
+	entryOpaqueWhiteout = "opaque_whiteout"
+)
+
+// Magic numbers of the compression formats of layers.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// errWalkDone stops walking a layer without error.
+var errWalkDone = errors.New("walk done")
+
+// walkLayer streams the layer described by desc as walkArchive does.
+func walkLayer(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, fn func(hdr *tar.Header, r io.Reader) error) error {
+	rc, err := repo.Blobs().Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return walkArchive(desc, rc, fn)
+}
+
+// walkArchive reads the layer described by desc from r, a tar archive
+// compressed by gzip or zstd or uncompressed, and calls fn with each entry and
+// the reader of its content. Walking stops without error if fn returns
+// errWalkDone.
+func walkArchive(desc ocispec.Descriptor, r io.Reader, fn func(hdr *tar.Header, r io.Reader) error) error {
+	dr, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress layer %s: %w", desc.Digest, err)
+	}
+	defer dr.Close()
+
+	tr := tar.NewReader(dr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read layer %s: %w", desc.Digest, err)
+		}
+		if err := fn(hdr, tr); err != nil {
+			if errors.Is(err, errWalkDone) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// decompress returns the reader of r decompressed by the compression format
+// detected by its magic number, or of r as is if it is not compressed.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// layerPath returns the absolute path of the name of a layer entry.
+func layerPath(name string) string {
+	return path.Join("/", name)
+}
+
+// underPath reports whether the absolute path p is the directory dir or under
+// it.
+func underPath(p, dir string) bool {
+	return dir == "/" || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// entryType returns the type of the layer entry, excluding whiteouts.
+func entryType(typeflag byte) string {
+	switch typeflag {
+	case tar.TypeReg:
+		return entryFile
+	case tar.TypeDir:
+		return entryDir
+	case tar.TypeSymlink:
+		return entrySymlink
+	case tar.TypeLink:
+		return entryHardlink
+	case tar.TypeChar:
+		return entryChar
+	case tar.TypeBlock:
+		return entryBlock
+	case tar.TypeFifo:
+		return entryFifo
+	default:
+		return entryOther
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestWalkArchive(t *testing.T) {
+	layer := buildLayer(
+		testEntry{name: "etc/"},
+		testEntry{name: "etc/hostname", content: "test"},
+		testEntry{name: "bin/sh", link: "busybox"},
+	)
+	tests := []struct {
+		name string
+		blob []byte
+	}{
+		{"uncompressed", layer},
+		{"gzip", gzipLayer(layer)},
+		{"zstd", zstdLayer(layer)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			var content []byte
+			err := walkArchive(ocispec.Descriptor{}, bytes.NewReader(tt.blob), func(hdr *tar.Header, r io.Reader) error {
+				names = append(names, hdr.Name)
+				if hdr.Name == "etc/hostname" {
+					var err error
+					content, err = io.ReadAll(r)
+					return err
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("walkArchive() error = %v", err)
+			}
+			if want := []string{"etc/", "etc/hostname", "bin/sh"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("walkArchive() walked %v, want %v", names, want)
+			}
+			if string(content) != "test" {
+				t.Errorf("walkArchive() read %q, want %q", content, "test")
+			}
+		})
+	}
+}
+
+func TestWalkArchive_Done(t *testing.T) {
+	layer := buildLayer(testEntry{name: "a"}, testEntry{name: "b"})
+	var names []string
+	err := walkArchive(ocispec.Descriptor{}, bytes.NewReader(layer), func(hdr *tar.Header, _ io.Reader) error {
+		names = append(names, hdr.Name)
+		return errWalkDone
+	})
+	if err != nil {
+		t.Fatalf("walkArchive() error = %v", err)
+	}
+	if want := []string{"a"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("walkArchive() walked %v, want %v", names, want)
+	}
+}
+
+func TestWalkArchive_Invalid(t *testing.T) {
+	err := walkArchive(ocispec.Descriptor{}, bytes.NewReader([]byte("not a tar archive")), func(*tar.Header, io.Reader) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("walkArchive() error = nil, want error")
+	}
+}
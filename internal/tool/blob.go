@@ -19,33 +19,49 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/oras-project/oras-mcp/internal/remote"
 	"oras.land/oras-go/v2/content"
-	"oras.land/oras-go/v2/registry"
 )
 
-// maxBlobSize defines the maximum blob size that can be fetched.
-const maxBlobSize = 4 * 1024 * 1024 // 4 MiB
-
 // MetadataFetchBlob describes the FetchBlob tool.
 var MetadataFetchBlob = &mcp.Tool{
 	Name:        "fetch_blob",
-	Description: "Fetch blob referenced by a digest in a manifest.",
+	Description: "Fetch blob referenced by a digest in a manifest. JSON blobs are returned as is, and other blobs, such as READMEs, signatures, or SBOMs in text formats, are returned encoded if an encoding is specified. Blobs too large to fetch at once can be read in parts by offset and length, or truncated.",
 	OutputSchema: &jsonschema.Schema{
 		Type:                 "object",
 		AdditionalProperties: &jsonschema.Schema{},
-		Description:          "Blob data in JSON format.",
+		Description:          "Blob data in JSON format, or the encoded blob content with its media type and size if an encoding is specified.",
 	},
 }
 
 // InputFetchBlob is the input for the FetchBlob tool.
 type InputFetchBlob struct {
-	Registry   string `json:"registry" jsonschema:"registry name"`
-	Repository string `json:"repository" jsonschema:"repository name"`
-	Digest     string `json:"digest" jsonschema:"blob digest"`
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string with the blob digest, as an alternative to registry, repository, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"blob digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx"`
+	Encoding   string `json:"encoding,omitempty" jsonschema:"encoding of the blob content, one of auto, text, base64, or hex, to fetch non-JSON blobs; auto returns text if the blob is valid UTF-8 and base64 otherwise; the JSON blob is returned as is if not specified"`
+	Offset     int64  `json:"offset,omitempty" jsonschema:"offset in bytes to read the blob from, returning the encoded content"`
+	Length     int64  `json:"length,omitempty" jsonschema:"number of bytes to read from the offset, to the end of the blob if not specified, returning the encoded content"`
+	Truncate   bool   `json:"truncate,omitempty" jsonschema:"return the first bytes of the content up to the size limit instead of failing if it is too large, returning the encoded content"`
+}
+
+// BlobContent is the encoded content of a blob.
+type BlobContent struct {
+	MediaType string `json:"mediaType" jsonschema:"media type of the blob reported by the registry"`
+	Size      int64  `json:"size" jsonschema:"blob size in bytes"`
+	Encoding  string `json:"encoding" jsonschema:"encoding of the content, text, base64, or hex"`
+	Content   string `json:"content" jsonschema:"blob content in the encoding"`
+	Offset    int64  `json:"offset,omitempty" jsonschema:"offset in bytes of the content in the blob, present if reading part of the blob"`
+	Length    int64  `json:"length,omitempty" jsonschema:"length in bytes of the content, present if reading part of the blob"`
+	Truncated bool   `json:"truncated,omitempty" jsonschema:"whether the content is truncated to the size limit, to continue reading at offset plus length"`
 }
 
 // OutputFetchBlob is the output for the FetchBlob tool.
@@ -70,24 +86,26 @@ func (o OutputFetchBlob) Raw() []byte {
 // FetchBlob fetches blob referenced by a digest in a manifest.
 func FetchBlob(ctx context.Context, _ *mcp.CallToolRequest, input InputFetchBlob) (*mcp.CallToolResult, OutputFetchBlob, error) {
 	// validate input
-	if input.Registry == "" {
-		return nil, OutputFetchBlob{}, fmt.Errorf("registry name is required")
-	}
-	if input.Repository == "" {
-		return nil, OutputFetchBlob{}, fmt.Errorf("repository name is required")
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, "", input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputFetchBlob{}, err
 	}
-	if input.Digest == "" {
+	if ref.ValidateReferenceAsDigest() != nil {
 		return nil, OutputFetchBlob{}, fmt.Errorf("blob digest is required")
 	}
-	ref := registry.Reference{
-		Registry:   input.Registry,
-		Repository: input.Repository,
-		Reference:  input.Digest,
+	if input.Encoding != "" && !slices.Contains(contentEncodings, input.Encoding) {
+		return nil, OutputFetchBlob{}, fmt.Errorf("unsupported encoding %q: must be one of %v", input.Encoding, contentEncodings)
 	}
-	if err := ref.Validate(); err != nil {
-		return nil, OutputFetchBlob{}, err
+	if input.Offset < 0 || input.Length < 0 {
+		return nil, OutputFetchBlob{}, fmt.Errorf("offset and length must not be negative")
+	}
+	// part of a blob is returned encoded as it is not a valid document
+	ranged := input.Offset != 0 || input.Length != 0 || input.Truncate
+	encoding := input.Encoding
+	if ranged && encoding == "" {
+		encoding = encodingAuto
 	}
-	repo := remote.NewRepository(ref)
+	repo := remote.NewRepository(ctx, ref)
 
 	// fetch the blob
 	desc, rc, err := repo.Blobs().FetchReference(ctx, ref.Reference)
@@ -95,22 +113,97 @@ func FetchBlob(ctx context.Context, _ *mcp.CallToolRequest, input InputFetchBlob
 		return nil, OutputFetchBlob{}, err
 	}
 	defer rc.Close()
-	if desc.Size > maxBlobSize {
-		return nil, OutputFetchBlob{}, fmt.Errorf("blob too large: %d", desc.Size)
+	var blobBytes []byte
+	blob := BlobContent{
+		MediaType: desc.MediaType,
+		Size:      desc.Size,
 	}
-	blobBytes, err := content.ReadAll(rc, desc)
-	if err != nil {
-		return nil, OutputFetchBlob{}, err
+	if ranged {
+		blobBytes, blob.Truncated, err = readBlobRange(rc, desc, input.Offset, input.Length, input.Truncate)
+		if err != nil {
+			return nil, OutputFetchBlob{}, err
+		}
+		blob.Offset = input.Offset
+		blob.Length = int64(len(blobBytes))
+	} else {
+		if desc.Size > maxBlobSize {
+			return nil, OutputFetchBlob{}, fmt.Errorf("blob too large: %d, specify offset and length or truncate to read part of it", desc.Size)
+		}
+		blobBytes, err = content.ReadAll(rc, desc)
+		if err != nil {
+			return nil, OutputFetchBlob{}, err
+		}
 	}
 
-	// only JSON blob is returned to the agent
-	if !json.Valid(blobBytes) {
-		return nil, OutputFetchBlob{}, fmt.Errorf("non-JSON blob is unsupported")
+	// return the encoded content if an encoding is specified, or only JSON
+	// blob otherwise
+	var output OutputFetchBlob
+	if encoding != "" {
+		blob.Encoding, blob.Content, err = encodeContentAs(blobBytes, encoding)
+		if err != nil {
+			return nil, OutputFetchBlob{}, err
+		}
+		output.blob, err = json.Marshal(blob)
+		if err != nil {
+			return nil, OutputFetchBlob{}, err
+		}
+	} else {
+		if !json.Valid(blobBytes) {
+			return nil, OutputFetchBlob{}, fmt.Errorf("non-JSON blob is unsupported, specify an encoding to fetch it")
+		}
+		output.blob = json.RawMessage(blobBytes)
 	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
 
-	// Create the output
-	output := OutputFetchBlob{
-		blob: json.RawMessage(blobBytes),
+// readBlobRange reads length bytes of the blob from offset, or to the end of
+// the blob if length is zero. A range larger than maxBlobSize fails unless
+// truncate is set, in which case its first maxBlobSize bytes are read and
+// reported as truncated. Only the content of the whole blob is verified
+// against its digest.
+func readBlobRange(r io.Reader, desc ocispec.Descriptor, offset, length int64, truncate bool) ([]byte, bool, error) {
+	if offset > desc.Size {
+		return nil, false, fmt.Errorf("offset %d is beyond the blob size %d", offset, desc.Size)
+	}
+	if remaining := desc.Size - offset; length == 0 || length > remaining {
+		length = remaining
+	}
+	var truncated bool
+	if length > maxBlobSize {
+		if !truncate {
+			return nil, false, fmt.Errorf("blob range too large: %d, specify a smaller length or truncate to read part of it", length)
+		}
+		length = maxBlobSize
+		truncated = true
+	}
+	if offset == 0 && length == desc.Size {
+		data, err := content.ReadAll(r, desc)
+		return data, false, err
+	}
+
+	if err := skipContent(r, offset); err != nil {
+		return nil, false, fmt.Errorf("failed to skip to offset %d: %w", offset, err)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, false, fmt.Errorf("failed to read blob range: %w", err)
+	}
+	return data, truncated, nil
+}
+
+// skipContent skips the first n bytes of the content read from r, seeking if
+// r is seekable, such as a blob fetched from a registry supporting range
+// requests, and discarding the content otherwise.
+func skipContent(r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		if _, err := seeker.Seek(n, io.SeekStart); err == nil {
+			return nil
+		}
 	}
-	return nil, output, nil
+	_, err := io.CopyN(io.Discard, r, n)
+	return err
 }
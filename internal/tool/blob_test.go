@@ -22,6 +22,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -29,6 +30,9 @@ import (
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/opencontainers/go-digest"
+	"github.com/oras-project/oras-mcp/internal/cache"
+	"github.com/oras-project/oras-mcp/internal/config"
+	"github.com/oras-project/oras-mcp/internal/remote"
 	"oras.land/oras-go/v2/content"
 )
 
@@ -46,7 +50,7 @@ func TestFetchBlob_OutputSchema(t *testing.T) {
 	if schema.Type != "object" {
 		t.Fatalf("unexpected schema type: got %q, want %q", schema.Type, "object")
 	}
-	if schema.Description != "Blob data in JSON format." {
+	if schema.Description != "Blob data in JSON format, or the encoded blob content with its media type and size if an encoding is specified." {
 		t.Fatalf("unexpected schema description: got %q", schema.Description)
 	}
 	if schema.AdditionalProperties == nil {
@@ -129,6 +133,27 @@ func TestFetchBlob_Success(t *testing.T) {
 	}
 }
 
+func TestFetchBlob_SuccessWithReference(t *testing.T) {
+	reg := newTestRegistry()
+	blob := []byte(`{"hello":"world"}`)
+	desc := reg.pushBlob("application/json", blob)
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+
+	result, output, err := FetchBlob(context.Background(), nil, InputFetchBlob{
+		Reference: getLocalhostServerURL(ts.URL) + "/test-repo@" + desc.Digest.String(),
+	})
+	if err != nil {
+		t.Fatalf("FetchBlob() error = %v", err)
+	}
+	if result != nil {
+		t.Fatalf("expected MCP result to be nil, got %v", result)
+	}
+	if !bytes.Equal(output.Raw(), blob) {
+		t.Fatalf("unexpected blob data: got %s, want %s", string(output.Raw()), string(blob))
+	}
+}
+
 func TestFetchBlob_InvalidInput(t *testing.T) {
 	testCases := []struct {
 		name  string
@@ -163,6 +188,19 @@ func TestFetchBlob_InvalidInput(t *testing.T) {
 				Digest:     "sha256:zzzz",
 			},
 		},
+		{
+			name: "reference without digest",
+			input: InputFetchBlob{
+				Reference: "localhost:5000/repo:latest",
+			},
+		},
+		{
+			name: "reference conflicts with components",
+			input: InputFetchBlob{
+				Reference: "localhost:5000/repo@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Digest:    "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+		},
 	}
 
 	ctx := context.Background()
@@ -256,8 +294,265 @@ func TestFetchBlob_NonJSONBlob(t *testing.T) {
 	}
 }
 
+func TestFetchBlob_Encoding(t *testing.T) {
+	reg := newTestRegistry()
+	text := reg.pushBlob("text/markdown", []byte("# README\n"))
+	binary := reg.pushBlob("application/octet-stream", []byte{0x00, 0xff})
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	tests := []struct {
+		name     string
+		digest   digest.Digest
+		encoding string
+		want     BlobContent
+	}{
+		{
+			name:     "auto text",
+			digest:   text.Digest,
+			encoding: "auto",
+			want:     BlobContent{MediaType: "application/octet-stream", Size: 9, Encoding: "text", Content: "# README\n"},
+		},
+		{
+			name:     "auto binary",
+			digest:   binary.Digest,
+			encoding: "auto",
+			want:     BlobContent{MediaType: "application/octet-stream", Size: 2, Encoding: "base64", Content: "AP8="},
+		},
+		{
+			name:     "text",
+			digest:   text.Digest,
+			encoding: "text",
+			want:     BlobContent{MediaType: "application/octet-stream", Size: 9, Encoding: "text", Content: "# README\n"},
+		},
+		{
+			name:     "hex",
+			digest:   binary.Digest,
+			encoding: "hex",
+			want:     BlobContent{MediaType: "application/octet-stream", Size: 2, Encoding: "hex", Content: "00ff"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := FetchBlob(context.Background(), nil, InputFetchBlob{
+				Reference: host + "/test-repo@" + tt.digest.String(),
+				Encoding:  tt.encoding,
+			})
+			if err != nil {
+				t.Fatalf("FetchBlob() error = %v", err)
+			}
+			var got BlobContent
+			if err := json.Unmarshal(output.Raw(), &got); err != nil {
+				t.Fatalf("failed to unmarshal output: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FetchBlob() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name     string
+		digest   digest.Digest
+		encoding string
+		errorMsg string
+	}{
+		{"binary as text", binary.Digest, "text", "content is not valid UTF-8 text"},
+		{"unsupported encoding", text.Digest, "utf-16", `unsupported encoding "utf-16"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := FetchBlob(context.Background(), nil, InputFetchBlob{
+				Reference: host + "/test-repo@" + tt.digest.String(),
+				Encoding:  tt.encoding,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("FetchBlob() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+			if len(output.Raw()) != 0 {
+				t.Fatalf("expected empty output on error, got %s", string(output.Raw()))
+			}
+		})
+	}
+}
+
+func TestFetchBlob_Range(t *testing.T) {
+	t.Cleanup(func() {
+		Configure(config.Default().Tool)
+	})
+	Configure(config.Tool{MaxBlobSize: 4})
+
+	reg := newTestRegistry()
+	desc := reg.pushBlob("text/plain", []byte("0123456789"))
+	var ranges []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			ranges = append(ranges, rangeHeader)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	reference := getLocalhostServerURL(ts.URL) + "/test-repo@" + desc.Digest.String()
+
+	tests := []struct {
+		name       string
+		input      InputFetchBlob
+		want       BlobContent
+		wantRanges []string
+	}{
+		{
+			name:       "offset and length",
+			input:      InputFetchBlob{Offset: 2, Length: 3},
+			want:       BlobContent{Encoding: "text", Content: "234", Offset: 2, Length: 3},
+			wantRanges: []string{"bytes=2-9"},
+		},
+		{
+			name:       "offset to the end",
+			input:      InputFetchBlob{Offset: 7, Encoding: "hex"},
+			want:       BlobContent{Encoding: "hex", Content: "373839", Offset: 7, Length: 3},
+			wantRanges: []string{"bytes=7-9"},
+		},
+		{
+			name:  "offset at the end",
+			input: InputFetchBlob{Offset: 10},
+			want:  BlobContent{Encoding: "text", Content: "", Offset: 10},
+		},
+		{
+			name:  "truncate",
+			input: InputFetchBlob{Truncate: true},
+			want:  BlobContent{Encoding: "text", Content: "0123", Length: 4, Truncated: true},
+		},
+		{
+			name:       "truncate from offset",
+			input:      InputFetchBlob{Offset: 3, Length: 6, Truncate: true},
+			want:       BlobContent{Encoding: "text", Content: "3456", Offset: 3, Length: 4, Truncated: true},
+			wantRanges: []string{"bytes=3-9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges = nil
+			tt.input.Reference = reference
+			_, output, err := FetchBlob(context.Background(), nil, tt.input)
+			if err != nil {
+				t.Fatalf("FetchBlob() error = %v", err)
+			}
+			var got BlobContent
+			if err := json.Unmarshal(output.Raw(), &got); err != nil {
+				t.Fatalf("failed to unmarshal output: %v", err)
+			}
+			tt.want.MediaType = "application/octet-stream"
+			tt.want.Size = 10
+			if got != tt.want {
+				t.Errorf("FetchBlob() = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(ranges, tt.wantRanges) {
+				t.Errorf("FetchBlob() range requests = %v, want %v", ranges, tt.wantRanges)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name     string
+		input    InputFetchBlob
+		errorMsg string
+	}{
+		{"negative offset", InputFetchBlob{Offset: -1}, "must not be negative"},
+		{"offset beyond the end", InputFetchBlob{Offset: 11}, "offset 11 is beyond the blob size 10"},
+		{"range too large", InputFetchBlob{Offset: 1, Length: 5}, "blob range too large: 5"},
+		{"blob too large", InputFetchBlob{}, "blob too large: 10"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Reference = reference
+			_, _, err := FetchBlob(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("FetchBlob() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestFetchBlob_RangeWithCache(t *testing.T) {
+	c, err := cache.New(context.Background(), t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	remote.SetCache(c)
+	t.Cleanup(func() {
+		remote.SetCache(nil)
+	})
+
+	reg := newTestRegistry()
+	desc := reg.pushBlob("text/plain", []byte("0123456789"))
+	var ranges []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			ranges = append(ranges, rangeHeader)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	_, output, err := FetchBlob(context.Background(), nil, InputFetchBlob{
+		Reference: getLocalhostServerURL(ts.URL) + "/test-repo@" + desc.Digest.String(),
+		Offset:    7,
+	})
+	if err != nil {
+		t.Fatalf("FetchBlob() error = %v", err)
+	}
+	var got BlobContent
+	if err := json.Unmarshal(output.Raw(), &got); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if got.Content != "789" {
+		t.Errorf("FetchBlob() content = %q, want %q", got.Content, "789")
+	}
+	if want := []string{"bytes=7-9"}; !slices.Equal(ranges, want) {
+		t.Errorf("FetchBlob() range requests = %v, want %v", ranges, want)
+	}
+	// part of a blob is not cached
+	if _, err := c.Resolve(context.Background(), desc.Digest.String()); err == nil {
+		t.Errorf("blob is cached after a ranged read")
+	}
+}
+
+func TestFetchBlob_RangeWithoutRangeRequests(t *testing.T) {
+	blob := []byte("0123456789")
+	dgst := digest.FromBytes(blob)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			t.Errorf("unexpected range request: %s", r.Header.Get("Range"))
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(blob); err != nil {
+			t.Errorf("failed to write blob: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	_, output, err := FetchBlob(context.Background(), nil, InputFetchBlob{
+		Reference: getLocalhostServerURL(ts.URL) + "/test-repo@" + dgst.String(),
+		Offset:    4,
+		Length:    2,
+	})
+	if err != nil {
+		t.Fatalf("FetchBlob() error = %v", err)
+	}
+	var got BlobContent
+	if err := json.Unmarshal(output.Raw(), &got); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if got.Content != "45" || got.Offset != 4 || got.Length != 2 {
+		t.Errorf("FetchBlob() = %+v, want content 45 at offset 4", got)
+	}
+}
+
 func TestFetchBlob_BlobTooLarge(t *testing.T) {
-	blob := bytes.Repeat([]byte("a"), maxBlobSize+1)
+	blob := bytes.Repeat([]byte("a"), int(maxBlobSize)+1)
 	dgst := digest.FromBytes(blob)
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,43 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import "github.com/oras-project/oras-mcp/internal/config"
+
+var (
+	// maxBlobSize defines the maximum blob size that can be fetched.
+	maxBlobSize int64
+
+	// wellknownRegistries lists the well-known public registries.
+	wellknownRegistries []Registry
+)
+
+func init() {
+	Configure(config.Default().Tool)
+}
+
+// Configure applies the configuration of the tools. It must not be called
+// concurrently with tool calls.
+func Configure(cfg config.Tool) {
+	maxBlobSize = cfg.MaxBlobSize
+	wellknownRegistries = make([]Registry, 0, len(cfg.WellknownRegistries))
+	for _, reg := range cfg.WellknownRegistries {
+		wellknownRegistries = append(wellknownRegistries, Registry{
+			Name:        reg.Name,
+			Description: reg.Description,
+		})
+	}
+}
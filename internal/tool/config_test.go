@@ -0,0 +1,53 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/oras-project/oras-mcp/internal/config"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() {
+		Configure(config.Default().Tool)
+	})
+
+	Configure(config.Tool{
+		MaxBlobSize: 1024,
+		WellknownRegistries: []config.WellknownRegistry{
+			{Name: "ghcr.io", Description: "GitHub Container Registry"},
+			{Name: "docker.io", Description: "Docker Hub"},
+		},
+	})
+
+	if maxBlobSize != 1024 {
+		t.Errorf("maxBlobSize = %d, want 1024", maxBlobSize)
+	}
+	_, output, err := ListWellknownRegistries(context.Background(), nil, InputListWellknownRegistries{})
+	if err != nil {
+		t.Fatalf("ListWellknownRegistries() error = %v", err)
+	}
+	want := []Registry{
+		{Name: "ghcr.io", Description: "GitHub Container Registry"},
+		{Name: "docker.io", Description: "Docker Hub"},
+	}
+	if !reflect.DeepEqual(output.Registries, want) {
+		t.Errorf("ListWellknownRegistries() = %v, want %v", output.Registries, want)
+	}
+}
@@ -0,0 +1,452 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// Statuses of the changes of files between images.
+const (
+	changeAdded    = "added"
+	changeRemoved  = "removed"
+	changeModified = "modified"
+)
+
+// MetadataDiffImageFilesystems describes the DiffImageFilesystems tool.
+var MetadataDiffImageFilesystems = &mcp.Tool{
+	Name:        "diff_image_filesystems",
+	Description: "Compare the filesystems of two container images, listing the added, removed, and modified files. Layers shared at the bottom of both images are only downloaded if needed to look up the files written, deleted, or hidden by one image alone.",
+}
+
+// InputDiffImageFilesystems is the input for the DiffImageFilesystems tool.
+type InputDiffImageFilesystems struct {
+	From      string `json:"from" jsonschema:"full reference string of the image to compare from"`
+	To        string `json:"to" jsonschema:"full reference string of the image to compare to"`
+	Normalize bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the references, such as nginx to docker.io/library/nginx:latest"`
+	Refresh   bool   `json:"refresh,omitempty" jsonschema:"resolve the tags against the registry instead of the tag cache"`
+	Platform  string `json:"platform,omitempty" jsonschema:"platform of the images, required if a manifest is an index, in the format of os/arch[/variant][:os_version] such as linux/arm64/v8"`
+	Prefix    string `json:"prefix,omitempty" jsonschema:"compare only the files at or under this path, such as /etc"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"maximum number of changes to list, 1000 by default and at most 10000"`
+}
+
+// OutputDiffImageFilesystems is the output for the DiffImageFilesystems tool.
+type OutputDiffImageFilesystems struct {
+	SharedLayers int          `json:"sharedLayers" jsonschema:"number of layers shared at the bottom of both images"`
+	Added        int          `json:"added" jsonschema:"number of added files"`
+	Removed      int          `json:"removed" jsonschema:"number of removed files"`
+	Modified     int          `json:"modified" jsonschema:"number of modified files"`
+	Changes      []FileChange `json:"changes" jsonschema:"changes of the files in the order of paths"`
+	Truncated    bool         `json:"truncated,omitempty" jsonschema:"whether more changes are left unlisted due to the limit"`
+}
+
+// FileChange is a change of a file between images.
+type FileChange struct {
+	Path   string     `json:"path" jsonschema:"absolute path of the file"`
+	Status string     `json:"status" jsonschema:"change status, one of added, removed, and modified"`
+	From   *FileState `json:"from,omitempty" jsonschema:"file in the image compared from, absent if added"`
+	To     *FileState `json:"to,omitempty" jsonschema:"file in the image compared to, absent if removed"`
+}
+
+// FileState is the state of a file in an image.
+type FileState struct {
+	Type       string `json:"type" jsonschema:"entry type, one of file, dir, symlink, hardlink, char, block, fifo, and other"`
+	Size       int64  `json:"size,omitempty" jsonschema:"file size in bytes"`
+	Mode       string `json:"mode,omitempty" jsonschema:"file mode and permission bits, such as -rwxr-xr-x"`
+	LinkTarget string `json:"linkTarget,omitempty" jsonschema:"target of the symlink or the hardlink"`
+	Digest     string `json:"digest,omitempty" jsonschema:"digest of the file content"`
+}
+
+// DiffImageFilesystems compares the filesystems of two container images.
+func DiffImageFilesystems(ctx context.Context, _ *mcp.CallToolRequest, input InputDiffImageFilesystems) (*mcp.CallToolResult, OutputDiffImageFilesystems, error) {
+	// validate input
+	if input.From == "" || input.To == "" {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("both from and to references are required")
+	}
+	fromRef, fromOriginal, err := artifactReference(input.From, "", "", "", "", input.Normalize)
+	if err != nil {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("from: %w", err)
+	}
+	toRef, toOriginal, err := artifactReference(input.To, "", "", "", "", input.Normalize)
+	if err != nil {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("to: %w", err)
+	}
+	if fromRef.Reference == "" || toRef.Reference == "" {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("either tag or digest is required in both references")
+	}
+	var platform *ocispec.Platform
+	if input.Platform != "" {
+		if platform, err = parsePlatform(input.Platform); err != nil {
+			return nil, OutputDiffImageFilesystems{}, err
+		}
+	}
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultEntryLimit
+	}
+	if limit < 0 || limit > maxEntryLimit {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("limit must be between 1 and %d", maxEntryLimit)
+	}
+	prefix := layerPath(input.Prefix)
+	fromRepo := remote.NewRepository(ctx, fromRef)
+	fromRepo.Refresh = input.Refresh
+	toRepo := remote.NewRepository(ctx, toRef)
+	toRepo.Refresh = input.Refresh
+
+	// resolve the images and skip the shared layers
+	_, fromManifest, err := resolveImage(ctx, fromRepo, fromRef.Reference, platform)
+	if err != nil {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("from: %w", err)
+	}
+	_, toManifest, err := resolveImage(ctx, toRepo, toRef.Reference, platform)
+	if err != nil {
+		return nil, OutputDiffImageFilesystems{}, fmt.Errorf("to: %w", err)
+	}
+	shared := 0
+	for shared < len(fromManifest.Layers) && shared < len(toManifest.Layers) && fromManifest.Layers[shared].Digest == toManifest.Layers[shared].Digest {
+		shared++
+	}
+
+	// apply the differing layers
+	from := newOverlay()
+	for _, layer := range fromManifest.Layers[shared:] {
+		if err := from.apply(ctx, fromRepo, layer); err != nil {
+			return nil, OutputDiffImageFilesystems{}, err
+		}
+	}
+	to := newOverlay()
+	for _, layer := range toManifest.Layers[shared:] {
+		if err := to.apply(ctx, toRepo, layer); err != nil {
+			return nil, OutputDiffImageFilesystems{}, err
+		}
+	}
+
+	// collect the paths touched by either image, and look up the paths left
+	// as is by one of them in the shared layers
+	paths := make(map[string]bool)
+	for _, o := range []*overlay{from, to} {
+		for p := range o.files {
+			paths[p] = true
+		}
+		for p := range o.deleted {
+			paths[p] = true
+		}
+	}
+	base := newOverlay()
+	base.paths = make(map[string]bool)
+	base.dirs = make(map[string]bool)
+	for p := range paths {
+		if !underPath(p, prefix) {
+			delete(paths, p)
+			continue
+		}
+		if _, ok := from.lookup(p); !ok {
+			base.paths[p] = true
+		} else if _, ok := to.lookup(p); !ok {
+			base.paths[p] = true
+		}
+	}
+	// the files of the shared layers under the directories deleted or hidden
+	// by one image alone are removed by it
+	for _, pair := range [][2]*overlay{{from, to}, {to, from}} {
+		o, other := pair[0], pair[1]
+		for _, hidden := range []map[string]bool{o.deleted, o.hidden} {
+			for dir := range hidden {
+				if (underPath(dir, prefix) || underPath(prefix, dir)) && !other.hides(dir) {
+					base.dirs[dir] = true
+				}
+			}
+		}
+	}
+	if shared > 0 && (len(base.paths) > 0 || len(base.dirs) > 0) {
+		for _, layer := range fromManifest.Layers[:shared] {
+			if err := base.apply(ctx, fromRepo, layer); err != nil {
+				return nil, OutputDiffImageFilesystems{}, err
+			}
+		}
+		for p := range base.files {
+			if underPath(p, prefix) {
+				paths[p] = true
+			}
+		}
+	}
+
+	// compare the paths
+	output := OutputDiffImageFilesystems{
+		SharedLayers: shared,
+		Changes:      []FileChange{},
+	}
+	for _, p := range slices.Sorted(maps.Keys(paths)) {
+		change, ok := diffFile(p, from, to, base)
+		if !ok {
+			continue
+		}
+		switch change.Status {
+		case changeAdded:
+			output.Added++
+		case changeRemoved:
+			output.Removed++
+		case changeModified:
+			output.Modified++
+		}
+		if len(output.Changes) == limit {
+			output.Truncated = true
+			continue
+		}
+		output.Changes = append(output.Changes, change)
+	}
+
+	// note how the requests to both repositories were served
+	fromReference, fromEndpoint := repositoryNotes(fromRepo, fromOriginal, "from")
+	toReference, toEndpoint := repositoryNotes(toRepo, toOriginal, "to")
+	result, err := noteResult(output, slices.Concat(fromReference, toReference, fromEndpoint, toEndpoint))
+	return result, output, err
+}
+
+// diffFile returns the change of the file at the path p from one overlay to
+// another, reporting false if it is unchanged. Paths left as is by an overlay
+// are looked up in the base overlay of the shared layers.
+func diffFile(p string, from, to, base *overlay) (FileChange, bool) {
+	fromState := lookupFile(p, from, base)
+	toState := lookupFile(p, to, base)
+	change := FileChange{
+		Path: p,
+		From: fromState,
+		To:   toState,
+	}
+	switch {
+	case fromState == nil && toState == nil:
+		return FileChange{}, false
+	case fromState == nil:
+		change.Status = changeAdded
+	case toState == nil:
+		change.Status = changeRemoved
+	case *fromState == *toState:
+		return FileChange{}, false
+	default:
+		change.Status = changeModified
+	}
+	return change, true
+}
+
+// lookupFile returns the state of the file at the path p in the overlay on top
+// of the base overlay, or nil if it is absent.
+func lookupFile(p string, o, base *overlay) *FileState {
+	if state, ok := o.lookup(p); ok {
+		return state
+	}
+	state, _ := base.lookup(p)
+	return state
+}
+
+// overlay is the filesystem changes made by a series of layers.
+type overlay struct {
+	// files are the files written by the layers.
+	files map[string]FileState
+	// deleted are the paths deleted by the layers.
+	deleted map[string]bool
+	// hidden are the directories whose contents in the layers below are
+	// hidden by opaque whiteouts or by being replaced by non-directories.
+	hidden map[string]bool
+	// children are the paths of the files and their ancestors by their
+	// parent directories, indexing the files under a directory.
+	children map[string]map[string]bool
+	// paths are the paths of the files to record, or nil to record all
+	// files, sparing the digests of the files not looked up.
+	paths map[string]bool
+	// dirs are the directories to record all files under, in addition to
+	// paths.
+	dirs map[string]bool
+}
+
+// newOverlay returns an empty overlay.
+func newOverlay() *overlay {
+	return &overlay{
+		files:    make(map[string]FileState),
+		deleted:  make(map[string]bool),
+		hidden:   make(map[string]bool),
+		children: make(map[string]map[string]bool),
+	}
+}
+
+// records reports whether the file at the path p is recorded by the overlay.
+func (o *overlay) records(p string) bool {
+	if o.paths == nil || o.paths[p] {
+		return true
+	}
+	for dir := parentPath(p); dir != "/"; dir = parentPath(dir) {
+		if o.dirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// hides reports whether the overlay deletes the path p or hides its contents
+// in the layers below.
+func (o *overlay) hides(p string) bool {
+	for dir := p; ; dir = parentPath(dir) {
+		if o.deleted[dir] || o.hidden[dir] {
+			return true
+		}
+		if dir == "/" {
+			return false
+		}
+	}
+}
+
+// apply streams the layer and applies its changes on top of the overlay.
+func (o *overlay) apply(ctx context.Context, repo *remote.Repository, layer ocispec.Descriptor) error {
+	// whiteouts only apply to the layers below regardless of their order in
+	// the layer, so the entries are collected before being applied
+	var files []LayerFile
+	states := make(map[string]FileState)
+	if err := walkLayer(ctx, repo, layer, func(hdr *tar.Header, r io.Reader) error {
+		file := layerFile(hdr)
+		files = append(files, file)
+		if file.Type == entryWhiteout || file.Type == entryOpaqueWhiteout || !o.records(file.Path) {
+			return nil
+		}
+		state := FileState{
+			Type:       file.Type,
+			Size:       file.Size,
+			Mode:       file.Mode,
+			LinkTarget: file.LinkTarget,
+		}
+		if file.Type == entryFile {
+			digester := digest.Canonical.Digester()
+			if _, err := io.Copy(digester.Hash(), r); err != nil {
+				return fmt.Errorf("failed to read %s from layer %s: %w", file.Path, layer.Digest, err)
+			}
+			state.Digest = digester.Digest().String()
+		}
+		states[file.Path] = state
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		switch file.Type {
+		case entryWhiteout:
+			o.remove(file.Path, true)
+			o.deleted[file.Path] = true
+		case entryOpaqueWhiteout:
+			o.remove(file.Path, false)
+			o.hidden[file.Path] = true
+		}
+	}
+	// non-directories replace any directory below them, including the ones
+	// implied by the paths of the files in the layers above
+	replaced := make(map[string]bool)
+	for _, file := range files {
+		switch file.Type {
+		case entryDir, entryWhiteout, entryOpaqueWhiteout:
+		default:
+			replaced[file.Path] = true
+		}
+	}
+	for p := range replaced {
+		o.remove(p, false)
+	}
+	for _, file := range files {
+		if file.Type == entryWhiteout || file.Type == entryOpaqueWhiteout {
+			continue
+		}
+		// the contents below a deleted or replaced directory stay hidden even
+		// if the directory is written again
+		if o.deleted[file.Path] {
+			delete(o.deleted, file.Path)
+			o.hidden[file.Path] = true
+		}
+		if file.Type != entryDir {
+			o.hidden[file.Path] = true
+		}
+		if state, ok := states[file.Path]; ok {
+			o.set(file.Path, state)
+		}
+	}
+	return nil
+}
+
+// set writes the file at the path p to the overlay, indexing it under its
+// ancestors.
+func (o *overlay) set(p string, state FileState) {
+	o.files[p] = state
+	for child := p; child != "/"; {
+		parent := parentPath(child)
+		siblings, ok := o.children[parent]
+		if !ok {
+			siblings = make(map[string]bool)
+			o.children[parent] = siblings
+		}
+		if siblings[child] {
+			break
+		}
+		siblings[child] = true
+		child = parent
+	}
+}
+
+// remove removes the files under the path p, including p itself if self is
+// set, from the overlay.
+func (o *overlay) remove(p string, self bool) {
+	for child := range o.children[p] {
+		o.remove(child, true)
+	}
+	delete(o.children, p)
+	if self {
+		delete(o.files, p)
+		delete(o.children[parentPath(p)], p)
+	}
+}
+
+// lookup returns the state of the file at the path p in the overlay, or nil if
+// it is deleted, reporting false if the overlay leaves the path as is in the
+// layers below.
+func (o *overlay) lookup(p string) (*FileState, bool) {
+	if state, ok := o.files[p]; ok {
+		return &state, true
+	}
+	for dir := p; ; {
+		if o.deleted[dir] || (dir != p && o.hidden[dir]) {
+			return nil, true
+		}
+		if dir == "/" {
+			return nil, false
+		}
+		dir = parentPath(dir)
+	}
+}
+
+// parentPath returns the parent directory of the absolute path p.
+func parentPath(p string) string {
+	if i := strings.LastIndexByte(p, '/'); i > 0 {
+		return p[:i]
+	}
+	return "/"
+}
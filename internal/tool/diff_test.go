@@ -0,0 +1,371 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/config"
+	"github.com/oras-project/oras-mcp/internal/remote"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestDiffImageFilesystems_SharedLayers(t *testing.T) {
+	reg := newTestRegistry()
+	platform := ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}}
+	base := reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+		testEntry{name: "etc/"},
+		testEntry{name: "etc/motd", content: "hello"},
+		testEntry{name: "etc/hosts", content: "hosts"},
+		testEntry{name: "etc/issue", content: "v1"},
+	)))
+	reg.manifests["from"] = reg.pushImage(platform, base, reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+		testEntry{name: "etc/"},
+		testEntry{name: "app/config", content: "v1"},
+		testEntry{name: "app/old", content: "old"},
+	))))
+	reg.manifests["to"] = reg.pushImage(platform, base, reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+		testEntry{name: "etc/"},
+		testEntry{name: "etc/.wh.motd"},
+		testEntry{name: "etc/hosts", content: "hosts"},
+		testEntry{name: "etc/issue", content: "v2"},
+		testEntry{name: "app/config", content: "v2"},
+		testEntry{name: "app/new", content: "new"},
+	))))
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	_, output, err := DiffImageFilesystems(context.Background(), nil, InputDiffImageFilesystems{
+		From: host + "/test-repo:from",
+		To:   host + "/test-repo:to",
+	})
+	if err != nil {
+		t.Fatalf("DiffImageFilesystems() error = %v", err)
+	}
+	want := OutputDiffImageFilesystems{
+		SharedLayers: 1,
+		Added:        1,
+		Removed:      2,
+		Modified:     2,
+		Changes: []FileChange{
+			{
+				Path:   "/app/config",
+				Status: "modified",
+				From:   &FileState{Type: "file", Size: 2, Mode: "-rw-r--r--", Digest: digest.FromString("v1").String()},
+				To:     &FileState{Type: "file", Size: 2, Mode: "-rw-r--r--", Digest: digest.FromString("v2").String()},
+			},
+			{
+				Path:   "/app/new",
+				Status: "added",
+				To:     &FileState{Type: "file", Size: 3, Mode: "-rw-r--r--", Digest: digest.FromString("new").String()},
+			},
+			{
+				Path:   "/app/old",
+				Status: "removed",
+				From:   &FileState{Type: "file", Size: 3, Mode: "-rw-r--r--", Digest: digest.FromString("old").String()},
+			},
+			{
+				// the file of the shared layers is modified by one image
+				Path:   "/etc/issue",
+				Status: "modified",
+				From:   &FileState{Type: "file", Size: 2, Mode: "-rw-r--r--", Digest: digest.FromString("v1").String()},
+				To:     &FileState{Type: "file", Size: 2, Mode: "-rw-r--r--", Digest: digest.FromString("v2").String()},
+			},
+			{
+				Path:   "/etc/motd",
+				Status: "removed",
+				From:   &FileState{Type: "file", Size: 5, Mode: "-rw-r--r--", Digest: digest.FromString("hello").String()},
+			},
+		},
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("DiffImageFilesystems() = %+v, want %+v", output, want)
+	}
+}
+
+func TestDiffImageFilesystems(t *testing.T) {
+	reg := newTestRegistry()
+	platform := ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}}
+	reg.manifests["from"] = reg.pushImage(platform, reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+		testEntry{name: "etc/os-release", content: "1"},
+		testEntry{name: "bin/sh", link: "busybox"},
+		testEntry{name: "usr/ssl/cert", content: "cert"},
+	))))
+	reg.manifests["to"] = reg.pushImage(platform, reg.pushBlob(ocispec.MediaTypeImageLayerZstd, zstdLayer(buildLayer(
+		testEntry{name: "etc/os-release", content: "2"},
+		testEntry{name: "bin/sh", link: "busybox"},
+		testEntry{name: "etc/new", content: "new"},
+	))))
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	_, output, err := DiffImageFilesystems(context.Background(), nil, InputDiffImageFilesystems{
+		From: host + "/test-repo:from",
+		To:   host + "/test-repo:to",
+	})
+	if err != nil {
+		t.Fatalf("DiffImageFilesystems() error = %v", err)
+	}
+	var got []string
+	for _, change := range output.Changes {
+		got = append(got, change.Status+" "+change.Path)
+	}
+	if want := []string{"added /etc/new", "modified /etc/os-release", "removed /usr/ssl/cert"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffImageFilesystems() changes = %v, want %v", got, want)
+	}
+	if output.SharedLayers != 0 || output.Added != 1 || output.Modified != 1 || output.Removed != 1 {
+		t.Errorf("DiffImageFilesystems() = %+v, want counts of 1 added, 1 modified, and 1 removed", output)
+	}
+
+	// the counts cover the changes left unlisted
+	_, output, err = DiffImageFilesystems(context.Background(), nil, InputDiffImageFilesystems{
+		From:   host + "/test-repo:from",
+		To:     host + "/test-repo:to",
+		Prefix: "/etc",
+		Limit:  1,
+	})
+	if err != nil {
+		t.Fatalf("DiffImageFilesystems() error = %v", err)
+	}
+	if len(output.Changes) != 1 || output.Changes[0].Path != "/etc/new" || !output.Truncated || output.Added != 1 || output.Modified != 1 || output.Removed != 0 {
+		t.Errorf("DiffImageFilesystems() = %+v, want /etc/new listed of 2 changes", output)
+	}
+}
+
+func TestDiffImageFilesystems_DirectoryWhiteouts(t *testing.T) {
+	reg := newTestRegistry()
+	platform := ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}}
+	base := reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "etc/"},
+		testEntry{name: "etc/conf.d/"},
+		testEntry{name: "etc/conf.d/a", content: "a"},
+		testEntry{name: "etc/conf.d/b", content: "b"},
+		testEntry{name: "etc/hosts", content: "hosts"},
+	))
+	reg.manifests["from"] = reg.pushImage(platform, base, reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "etc/motd", content: "hello"},
+	)))
+	reg.manifests["opaque"] = reg.pushImage(platform, base, reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "etc/conf.d/"},
+		testEntry{name: "etc/conf.d/.wh..wh..opq"},
+		testEntry{name: "etc/conf.d/b", content: "b"},
+	)))
+	reg.manifests["deleted"] = reg.pushImage(platform, base, reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "etc/.wh.conf.d"},
+	)))
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	tests := []struct {
+		name string
+		to   string
+		want []string
+	}{
+		{
+			name: "opaque whiteout",
+			to:   "opaque",
+			want: []string{"removed /etc/conf.d/a", "removed /etc/motd"},
+		},
+		{
+			name: "directory whiteout",
+			to:   "deleted",
+			want: []string{"removed /etc/conf.d", "removed /etc/conf.d/a", "removed /etc/conf.d/b", "removed /etc/motd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := DiffImageFilesystems(context.Background(), nil, InputDiffImageFilesystems{
+				From: host + "/test-repo:from",
+				To:   host + "/test-repo:" + tt.to,
+			})
+			if err != nil {
+				t.Fatalf("DiffImageFilesystems() error = %v", err)
+			}
+			var got []string
+			for _, change := range output.Changes {
+				got = append(got, change.Status+" "+change.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffImageFilesystems() changes = %v, want %v", got, tt.want)
+			}
+			if output.Removed != len(tt.want) {
+				t.Errorf("DiffImageFilesystems() removed = %d, want %d", output.Removed, len(tt.want))
+			}
+		})
+	}
+
+	// the files under the directory are limited to the prefix
+	_, output, err := DiffImageFilesystems(context.Background(), nil, InputDiffImageFilesystems{
+		From:   host + "/test-repo:from",
+		To:     host + "/test-repo:deleted",
+		Prefix: "/etc/conf.d/a",
+	})
+	if err != nil {
+		t.Fatalf("DiffImageFilesystems() error = %v", err)
+	}
+	if len(output.Changes) != 1 || output.Changes[0].Path != "/etc/conf.d/a" || output.Changes[0].Status != "removed" {
+		t.Errorf("DiffImageFilesystems() = %+v, want /etc/conf.d/a removed", output)
+	}
+}
+
+func TestDiffImageFilesystems_Result(t *testing.T) {
+	reg := newTestRegistry()
+	platform := ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}}
+	reg.manifests["from"] = reg.pushImage(platform, reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "etc/os-release", content: "1"},
+	)))
+	reg.manifests["latest"] = reg.pushImage(platform, reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "etc/os-release", content: "2"},
+	)))
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	t.Cleanup(func() {
+		remote.Configure(config.Default().Registry)
+	})
+	cfg := config.Default().Registry
+	cfg.Mirrors = []config.RegistryMirror{
+		{
+			Prefix:  "registry.example",
+			Mirrors: []string{host},
+		},
+	}
+	remote.Configure(cfg)
+
+	result, output, err := DiffImageFilesystems(context.Background(), nil, InputDiffImageFilesystems{
+		From:      "registry.example/test-repo:from",
+		To:        host + "/test-repo",
+		Normalize: true,
+	})
+	if err != nil {
+		t.Fatalf("DiffImageFilesystems() error = %v", err)
+	}
+	if output.Modified != 1 {
+		t.Errorf("DiffImageFilesystems() = %+v, want 1 modified", output)
+	}
+	if result == nil {
+		t.Fatal("Expected result to report the reference and the endpoint")
+	}
+	want := map[string]any{
+		"toReference":  host + "/test-repo:latest",
+		"fromEndpoint": host + "/test-repo",
+	}
+	if !reflect.DeepEqual(map[string]any(result.Meta), want) {
+		t.Errorf("Meta = %v, want %v", result.Meta, want)
+	}
+}
+
+func TestDiffImageFilesystems_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    InputDiffImageFilesystems
+		errorMsg string
+	}{
+		{
+			name:     "missing to",
+			input:    InputDiffImageFilesystems{From: "localhost:5000/test-repo:v1"},
+			errorMsg: "both from and to references are required",
+		},
+		{
+			name:     "invalid from",
+			input:    InputDiffImageFilesystems{From: "localhost:5000/INVALID:v1", To: "localhost:5000/test-repo:v2"},
+			errorMsg: "from: invalid reference string format",
+		},
+		{
+			name:     "missing tag",
+			input:    InputDiffImageFilesystems{From: "localhost:5000/test-repo:v1", To: "localhost:5000/test-repo"},
+			errorMsg: "either tag or digest is required",
+		},
+		{
+			name:     "invalid limit",
+			input:    InputDiffImageFilesystems{From: "localhost:5000/test-repo:v1", To: "localhost:5000/test-repo:v2", Limit: -1},
+			errorMsg: "limit must be between 1 and 10000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := DiffImageFilesystems(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("DiffImageFilesystems() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	reg := newTestRegistry()
+	layers := []ocispec.Descriptor{
+		reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+			testEntry{name: "a/b", content: "b"},
+			testEntry{name: "c/d", content: "d"},
+			testEntry{name: "e/f", content: "f"},
+		)),
+		reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+			// entries come before the whiteouts of the same layer
+			testEntry{name: "a/g", content: "g"},
+			testEntry{name: "a/.wh..wh..opq"},
+			testEntry{name: ".wh.c"},
+			testEntry{name: "e", content: "file replacing a directory"},
+		)),
+		reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+			testEntry{name: "c/"},
+		)),
+	}
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	repo := remote.NewRepository(context.Background(), registry.Reference{
+		Registry:   getLocalhostServerURL(ts.URL),
+		Repository: "test-repo",
+	})
+
+	o := newOverlay()
+	for _, layer := range layers {
+		if err := o.apply(context.Background(), repo, layer); err != nil {
+			t.Fatalf("apply() error = %v", err)
+		}
+	}
+	tests := []struct {
+		path    string
+		present bool
+		ok      bool
+	}{
+		{"/a/b", false, true},
+		{"/a/g", true, true},
+		{"/a/x", false, true},
+		{"/a", false, false},
+		{"/c", true, true},
+		{"/c/d", false, true},
+		{"/e", true, true},
+		{"/e/f", false, true},
+		{"/x", false, false},
+	}
+	for _, tt := range tests {
+		state, ok := o.lookup(tt.path)
+		if (state != nil) != tt.present || ok != tt.ok {
+			t.Errorf("lookup(%s) = %v, %v, want present %v, %v", tt.path, state, ok, tt.present, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Encodings of binary content in the tool outputs.
+const (
+	encodingAuto   = "auto"
+	encodingText   = "text"
+	encodingBase64 = "base64"
+	encodingHex    = "hex"
+)
+
+// contentEncodings lists the encodings accepted by encodeContentAs.
+var contentEncodings = []string{encodingAuto, encodingText, encodingBase64, encodingHex}
+
+// encodeContent encodes the content as text if it is valid UTF-8, or in base64
+// otherwise, returning the encoding and the encoded content.
+func encodeContent(data []byte) (string, string) {
+	if utf8.Valid(data) {
+		return encodingText, string(data)
+	}
+	return encodingBase64, base64.StdEncoding.EncodeToString(data)
+}
+
+// encodeContentAs encodes the content in the given encoding, choosing between
+// text and base64 by UTF-8 validity if it is auto, and returns the encoding
+// used and the encoded content.
+func encodeContentAs(data []byte, encoding string) (string, string, error) {
+	switch encoding {
+	case encodingAuto:
+		encoding, content := encodeContent(data)
+		return encoding, content, nil
+	case encodingText:
+		if !utf8.Valid(data) {
+			return "", "", errors.New("content is not valid UTF-8 text, use the base64 or hex encoding instead")
+		}
+		return encodingText, string(data), nil
+	case encodingBase64:
+		return encodingBase64, base64.StdEncoding.EncodeToString(data), nil
+	case encodingHex:
+		return encodingHex, hex.EncodeToString(data), nil
+	default:
+		return "", "", fmt.Errorf("unsupported encoding %q: must be one of %v", encoding, contentEncodings)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantEncoding string
+		wantContent  string
+	}{
+		{"text", []byte("hello\n"), "text", "hello\n"},
+		{"empty", []byte{}, "text", ""},
+		{"binary", []byte{0xff, 0xfe}, "base64", "//4="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, content := encodeContent(tt.data)
+			if encoding != tt.wantEncoding || content != tt.wantContent {
+				t.Errorf("encodeContent() = %s, %q, want %s, %q", encoding, content, tt.wantEncoding, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestEncodeContentAs(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		encoding     string
+		wantEncoding string
+		wantContent  string
+		wantErr      string
+	}{
+		{"auto text", []byte("hello"), "auto", "text", "hello", ""},
+		{"auto binary", []byte{0xff}, "auto", "base64", "/w==", ""},
+		{"text", []byte("hello"), "text", "text", "hello", ""},
+		{"invalid text", []byte{0xff}, "text", "", "", "not valid UTF-8"},
+		{"base64", []byte("hello"), "base64", "base64", "aGVsbG8=", ""},
+		{"hex", []byte("hello"), "hex", "hex", "68656c6c6f", ""},
+		{"unsupported", []byte("hello"), "base32", "", "", "unsupported encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, content, err := encodeContentAs(tt.data, tt.encoding)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("encodeContentAs() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encodeContentAs() error = %v", err)
+			}
+			if encoding != tt.wantEncoding || content != tt.wantContent {
+				t.Errorf("encodeContentAs() = %s, %q, want %s, %q", encoding, content, tt.wantEncoding, tt.wantContent)
+			}
+		})
+	}
+}
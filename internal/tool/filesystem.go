@@ -0,0 +1,359 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// maxLinkHops is the maximum number of links followed to resolve a path.
+const maxLinkHops = 40
+
+// errFileNotFound is returned if a file is not found in an image.
+var errFileNotFound = errors.New("file not found")
+
+// MetadataReadImageFile describes the ReadImageFile tool.
+var MetadataReadImageFile = &mcp.Tool{
+	Name:        "read_image_file",
+	Description: "Read a file from the filesystem of a container image merged from its layers, or from a single layer, without pulling the image.",
+}
+
+// InputReadImageFile is the input for the ReadImageFile tool.
+type InputReadImageFile struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
+	Platform   string `json:"platform,omitempty" jsonschema:"platform of the image, required if the manifest is an index, in the format of os/arch[/variant][:os_version] such as linux/arm64/v8"`
+	Path       string `json:"path" jsonschema:"absolute path of the file, such as /etc/os-release"`
+	Layer      string `json:"layer,omitempty" jsonschema:"digest of the layer to read the file from instead of the merged filesystem, without following links"`
+}
+
+// OutputReadImageFile is the output for the ReadImageFile tool.
+type OutputReadImageFile struct {
+	Path       string `json:"path" jsonschema:"absolute path of the file read, after following links"`
+	Layer      string `json:"layer" jsonschema:"digest of the layer providing the file"`
+	Type       string `json:"type" jsonschema:"entry type, one of file, dir, symlink, hardlink, char, block, fifo, and other"`
+	Size       int64  `json:"size,omitempty" jsonschema:"file size in bytes"`
+	Mode       string `json:"mode,omitempty" jsonschema:"file mode and permission bits, such as -rwxr-xr-x"`
+	LinkTarget string `json:"linkTarget,omitempty" jsonschema:"target of the link, if the file read from a single layer is a link"`
+	Encoding   string `json:"encoding,omitempty" jsonschema:"encoding of the content, text if it is valid UTF-8 or base64 otherwise"`
+	Content    string `json:"content,omitempty" jsonschema:"file content, present for regular files"`
+}
+
+// ReadImageFile reads a file from the filesystem of a container image.
+func ReadImageFile(ctx context.Context, _ *mcp.CallToolRequest, input InputReadImageFile) (*mcp.CallToolResult, OutputReadImageFile, error) {
+	// validate input
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputReadImageFile{}, err
+	}
+	if ref.Reference == "" {
+		return nil, OutputReadImageFile{}, fmt.Errorf("either tag or digest is required")
+	}
+	if input.Path == "" {
+		return nil, OutputReadImageFile{}, fmt.Errorf("file path is required")
+	}
+	var platform *ocispec.Platform
+	if input.Platform != "" {
+		if platform, err = parsePlatform(input.Platform); err != nil {
+			return nil, OutputReadImageFile{}, err
+		}
+	}
+	if input.Layer != "" {
+		if _, err := digest.Parse(input.Layer); err != nil {
+			return nil, OutputReadImageFile{}, fmt.Errorf("invalid layer digest: %w", err)
+		}
+	}
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
+
+	// find the file in the layers
+	_, manifest, err := resolveImage(ctx, repo, ref.Reference, platform)
+	if err != nil {
+		return nil, OutputReadImageFile{}, err
+	}
+	var output OutputReadImageFile
+	if input.Layer != "" {
+		i := slices.IndexFunc(manifest.Layers, func(layer ocispec.Descriptor) bool {
+			return layer.Digest.String() == input.Layer
+		})
+		if i < 0 {
+			return nil, OutputReadImageFile{}, fmt.Errorf("layer %s is not in the image", input.Layer)
+		}
+		output, err = readLayerFile(ctx, repo, manifest.Layers[i], layerPath(input.Path))
+	} else {
+		output, err = readMergedFile(ctx, repo, manifest.Layers, layerPath(input.Path))
+	}
+	if err != nil {
+		return nil, OutputReadImageFile{}, err
+	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
+
+// readLayerFile reads the file at the absolute path p from the layer.
+func readLayerFile(ctx context.Context, repo *remote.Repository, layer ocispec.Descriptor, p string) (OutputReadImageFile, error) {
+	var output *OutputReadImageFile
+	var deleted bool
+	if err := walkLayer(ctx, repo, layer, func(hdr *tar.Header, r io.Reader) error {
+		file := layerFile(hdr)
+		switch {
+		case file.Type == entryWhiteout:
+			deleted = deleted || underPath(p, file.Path)
+		case file.Type != entryOpaqueWhiteout && file.Path == p:
+			entry, err := readEntry(layer, file, r)
+			if err != nil {
+				return err
+			}
+			output = &entry
+			return errWalkDone
+		}
+		return nil
+	}); err != nil {
+		return OutputReadImageFile{}, err
+	}
+	if output != nil {
+		return *output, nil
+	}
+	if deleted {
+		return OutputReadImageFile{}, fmt.Errorf("%s is deleted by layer %s", p, layer.Digest)
+	}
+	return OutputReadImageFile{}, fmt.Errorf("%w: %s in layer %s", errFileNotFound, p, layer.Digest)
+}
+
+// readMergedFile reads the file at the absolute path p from the filesystem
+// merged from the layers, following links.
+func readMergedFile(ctx context.Context, repo *remote.Repository, layers []ocispec.Descriptor, p string) (OutputReadImageFile, error) {
+	fs := &mergedFilesystem{
+		repo:    repo,
+		layers:  layers,
+		indexes: make([]*layerIndex, len(layers)),
+		wanted:  make(map[string]bool),
+	}
+	for range maxLinkHops {
+		fs.wanted[p] = true
+		i, file, next, err := fs.lookup(ctx, p)
+		if err != nil {
+			return OutputReadImageFile{}, err
+		}
+		if next == "" {
+			return fs.read(ctx, i, file)
+		}
+		p = next
+	}
+	return OutputReadImageFile{}, fmt.Errorf("too many levels of links resolving %s", p)
+}
+
+// layerIndex is the record of the entries of a layer.
+type layerIndex struct {
+	// files are the entries other than whiteouts by path.
+	files map[string]LayerFile
+	// deleted are the paths deleted by whiteouts.
+	deleted map[string]bool
+	// opaque are the directories made opaque by opaque whiteouts.
+	opaque map[string]bool
+	// contents are the contents of the regular files at the wanted paths.
+	contents map[string][]byte
+}
+
+// mergedFilesystem is the filesystem merged from the layers of an image. Each
+// layer is walked at most once, recording its entries to resolve links from.
+type mergedFilesystem struct {
+	repo    *remote.Repository
+	layers  []ocispec.Descriptor
+	indexes []*layerIndex
+	// wanted are the paths looked up so far, whose contents are kept while
+	// walking the layers.
+	wanted map[string]bool
+}
+
+// index returns the record of the i-th layer, walking the layer if it is not
+// recorded yet.
+func (fs *mergedFilesystem) index(ctx context.Context, i int) (*layerIndex, error) {
+	if idx := fs.indexes[i]; idx != nil {
+		return idx, nil
+	}
+	layer := fs.layers[i]
+	idx := &layerIndex{
+		files:    make(map[string]LayerFile),
+		deleted:  make(map[string]bool),
+		opaque:   make(map[string]bool),
+		contents: make(map[string][]byte),
+	}
+	if err := walkLayer(ctx, fs.repo, layer, func(hdr *tar.Header, r io.Reader) error {
+		file := layerFile(hdr)
+		switch file.Type {
+		case entryWhiteout:
+			idx.deleted[file.Path] = true
+		case entryOpaqueWhiteout:
+			idx.opaque[file.Path] = true
+		default:
+			idx.files[file.Path] = file
+			if file.Type == entrySymlink {
+				fs.follow(file)
+			}
+			if file.Type == entryFile && fs.wanted[file.Path] && file.Size <= maxBlobSize {
+				data, err := io.ReadAll(io.LimitReader(r, file.Size))
+				if err != nil {
+					return fmt.Errorf("failed to read %s from layer %s: %w", file.Path, layer.Digest, err)
+				}
+				idx.contents[file.Path] = data
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	fs.indexes[i] = idx
+	return idx, nil
+}
+
+// follow marks the paths the wanted paths lead to through the symlink as
+// wanted, so that the contents of the link targets coming later in the layers
+// are kept on the same walk.
+func (fs *mergedFilesystem) follow(link LayerFile) {
+	var targets []string
+	for p := range fs.wanted {
+		if underPath(p, link.Path) {
+			targets = append(targets, path.Join(resolveLink(link.Path, link.LinkTarget), strings.TrimPrefix(p, link.Path)))
+		}
+	}
+	for _, target := range targets {
+		fs.wanted[target] = true
+	}
+}
+
+// lookup looks up the file at the absolute path p, returning the index of the
+// layer providing it and its entry, or the path to look up instead if p is a
+// symlink or under a symlinked directory. Layers are searched from the top, as
+// the topmost layer providing, deleting, or hiding p decides it.
+func (fs *mergedFilesystem) lookup(ctx context.Context, p string) (int, LayerFile, string, error) {
+	// the ancestors of p that are directories in the layers above
+	dirs := make(map[string]bool)
+	for i := len(fs.layers) - 1; i >= 0; i-- {
+		idx, err := fs.index(ctx, i)
+		if err != nil {
+			return 0, LayerFile{}, "", err
+		}
+		if file, ok := idx.files[p]; ok {
+			switch file.Type {
+			case entrySymlink:
+				return 0, LayerFile{}, resolveLink(p, file.LinkTarget), nil
+			case entryHardlink:
+				// hardlinks refer to the entries of their own layer
+				target, ok := idx.files[file.LinkTarget]
+				if !ok {
+					return 0, LayerFile{}, "", fmt.Errorf("%w: %s linked from %s in layer %s", errFileNotFound, file.LinkTarget, p, fs.layers[i].Digest)
+				}
+				if target.Type == entrySymlink {
+					return 0, LayerFile{}, resolveLink(target.Path, target.LinkTarget), nil
+				}
+				return i, target, "", nil
+			default:
+				return i, file, "", nil
+			}
+		}
+		for dir := parentPath(p); dir != "/"; dir = parentPath(dir) {
+			file, ok := idx.files[dir]
+			if !ok || file.Type == entryDir {
+				continue
+			}
+			// a parent directory is replaced by a non-directory, which is in
+			// turn replaced if the layers above have a directory there
+			if file.Type == entrySymlink && !dirs[dir] {
+				return 0, LayerFile{}, path.Join(resolveLink(dir, file.LinkTarget), strings.TrimPrefix(p, dir)), nil
+			}
+			return 0, LayerFile{}, "", fmt.Errorf("%w: %s", errFileNotFound, p)
+		}
+		for dir := p; ; dir = parentPath(dir) {
+			// opaque directories only hide their contents in the layers below
+			if idx.deleted[dir] || (dir != p && idx.opaque[dir]) {
+				return 0, LayerFile{}, "", fmt.Errorf("%w: %s", errFileNotFound, p)
+			}
+			if file, ok := idx.files[dir]; ok && file.Type == entryDir {
+				dirs[dir] = true
+			}
+			if dir == "/" {
+				break
+			}
+		}
+	}
+	return 0, LayerFile{}, "", fmt.Errorf("%w: %s", errFileNotFound, p)
+}
+
+// read reads the file entry of the i-th layer, walking the layer again only if
+// its content came before the file was looked up.
+func (fs *mergedFilesystem) read(ctx context.Context, i int, file LayerFile) (OutputReadImageFile, error) {
+	layer := fs.layers[i]
+	if data, ok := fs.indexes[i].contents[file.Path]; ok {
+		return readEntry(layer, file, bytes.NewReader(data))
+	}
+	if file.Type != entryFile || file.Size > maxBlobSize {
+		return readEntry(layer, file, nil)
+	}
+	return readLayerFile(ctx, fs.repo, layer, file.Path)
+}
+
+// resolveLink returns the absolute path of the target of the symlink at the
+// absolute path p.
+func resolveLink(p, target string) string {
+	if path.IsAbs(target) {
+		return path.Clean(target)
+	}
+	return layerPath(path.Join(path.Dir(p), target))
+}
+
+// readEntry returns the layer entry with the content read from r if it is a
+// regular file.
+func readEntry(layer ocispec.Descriptor, file LayerFile, r io.Reader) (OutputReadImageFile, error) {
+	output := OutputReadImageFile{
+		Path:       file.Path,
+		Layer:      layer.Digest.String(),
+		Type:       file.Type,
+		Size:       file.Size,
+		Mode:       file.Mode,
+		LinkTarget: file.LinkTarget,
+	}
+	if file.Type != entryFile {
+		return output, nil
+	}
+	if file.Size > maxBlobSize {
+		return OutputReadImageFile{}, fmt.Errorf("file too large: %d", file.Size)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, file.Size))
+	if err != nil {
+		return OutputReadImageFile{}, fmt.Errorf("failed to read %s from layer %s: %w", file.Path, layer.Digest, err)
+	}
+	output.Encoding, output.Content = encodeContent(data)
+	return output, nil
+}
@@ -0,0 +1,330 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/config"
+)
+
+// pushTestFilesystem pushes the image test-repo:fs of three layers, returning
+// the layers.
+func pushTestFilesystem(reg *testRegistry) []ocispec.Descriptor {
+	layers := []ocispec.Descriptor{
+		reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+			testEntry{name: "etc/"},
+			testEntry{name: "etc/os-release", link: "../usr/lib/os-release"},
+			testEntry{name: "etc/motd", content: "hello"},
+			testEntry{name: "etc/ssl/cert", content: "cert"},
+			testEntry{name: "usr/lib/os-release", content: "ID=test\n"},
+			testEntry{name: "lib", link: "usr/lib"},
+			testEntry{name: "bin/tool", content: "\xff\xfe"},
+			testEntry{name: "loop/a", link: "b"},
+			testEntry{name: "loop/b", link: "a"},
+		))),
+		reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+			testEntry{name: "etc/.wh.motd"},
+			testEntry{name: "etc/ssl/.wh..wh..opq"},
+			testEntry{name: "etc/ssl/new", content: "new"},
+			testEntry{name: "etc/hostname", content: "host1"},
+		))),
+		reg.pushBlob(ocispec.MediaTypeImageLayerZstd, zstdLayer(buildLayer(
+			testEntry{name: "etc/hostname", content: "host2"},
+		))),
+	}
+	image := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}}, layers...)
+	reg.manifests["fs"] = image
+	return layers
+}
+
+func TestReadImageFile(t *testing.T) {
+	reg := newTestRegistry()
+	layers := pushTestFilesystem(reg)
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	reference := getLocalhostServerURL(ts.URL) + "/test-repo:fs"
+
+	tests := []struct {
+		path  string
+		layer string
+		want  OutputReadImageFile
+	}{
+		{
+			path: "/etc/os-release",
+			want: OutputReadImageFile{Path: "/usr/lib/os-release", Layer: layers[0].Digest.String(), Type: "file", Size: 8, Mode: "-rw-r--r--", Encoding: "text", Content: "ID=test\n"},
+		},
+		{
+			path: "lib/os-release",
+			want: OutputReadImageFile{Path: "/usr/lib/os-release", Layer: layers[0].Digest.String(), Type: "file", Size: 8, Mode: "-rw-r--r--", Encoding: "text", Content: "ID=test\n"},
+		},
+		{
+			path: "/etc/hostname",
+			want: OutputReadImageFile{Path: "/etc/hostname", Layer: layers[2].Digest.String(), Type: "file", Size: 5, Mode: "-rw-r--r--", Encoding: "text", Content: "host2"},
+		},
+		{
+			path: "/etc/ssl/new",
+			want: OutputReadImageFile{Path: "/etc/ssl/new", Layer: layers[1].Digest.String(), Type: "file", Size: 3, Mode: "-rw-r--r--", Encoding: "text", Content: "new"},
+		},
+		{
+			path: "/bin/tool",
+			want: OutputReadImageFile{Path: "/bin/tool", Layer: layers[0].Digest.String(), Type: "file", Size: 2, Mode: "-rw-r--r--", Encoding: "base64", Content: "//4="},
+		},
+		{
+			path: "/etc",
+			want: OutputReadImageFile{Path: "/etc", Layer: layers[0].Digest.String(), Type: "dir", Mode: "drwxr-xr-x"},
+		},
+		{
+			path:  "/etc/hostname",
+			layer: layers[1].Digest.String(),
+			want:  OutputReadImageFile{Path: "/etc/hostname", Layer: layers[1].Digest.String(), Type: "file", Size: 5, Mode: "-rw-r--r--", Encoding: "text", Content: "host1"},
+		},
+		{
+			path:  "/etc/os-release",
+			layer: layers[0].Digest.String(),
+			want:  OutputReadImageFile{Path: "/etc/os-release", Layer: layers[0].Digest.String(), Type: "symlink", Mode: "Lrwxrwxrwx", LinkTarget: "../usr/lib/os-release"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+tt.layer, func(t *testing.T) {
+			_, output, err := ReadImageFile(context.Background(), nil, InputReadImageFile{
+				Reference: reference,
+				Path:      tt.path,
+				Layer:     tt.layer,
+			})
+			if err != nil {
+				t.Fatalf("ReadImageFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(output, tt.want) {
+				t.Errorf("ReadImageFile() = %+v, want %+v", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadImageFile_Links(t *testing.T) {
+	reg := newTestRegistry()
+	platform := ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}}
+	base := reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "bin/a", content: "A"},
+		testEntry{name: "bin/b", hardlink: "bin/a"},
+		testEntry{name: "data/f", content: "lower"},
+		testEntry{name: "real/f", content: "real"},
+	))
+	override := reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "bin/a", content: "override"},
+	))
+	deleted := reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "bin/.wh.a"},
+	))
+	symlinked := reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: ".wh.data"},
+		testEntry{name: "data", link: "real"},
+	))
+	replaced := reg.pushBlob(ocispec.MediaTypeImageLayer, buildLayer(
+		testEntry{name: "data/"},
+		testEntry{name: "data/g", content: "g"},
+	))
+	reg.manifests["override"] = reg.pushImage(platform, base, override)
+	reg.manifests["deleted"] = reg.pushImage(platform, base, deleted)
+	reg.manifests["symlinked"] = reg.pushImage(platform, base, symlinked)
+	reg.manifests["replaced"] = reg.pushImage(platform, base, symlinked, replaced)
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	tests := []struct {
+		tag      string
+		path     string
+		want     OutputReadImageFile
+		errorMsg string
+	}{
+		{
+			// hardlinks are resolved in their own layer
+			tag:  "override",
+			path: "/bin/b",
+			want: OutputReadImageFile{Path: "/bin/a", Layer: base.Digest.String(), Type: "file", Size: 1, Mode: "-rw-r--r--", Encoding: "text", Content: "A"},
+		},
+		{
+			tag:  "override",
+			path: "/bin/a",
+			want: OutputReadImageFile{Path: "/bin/a", Layer: override.Digest.String(), Type: "file", Size: 8, Mode: "-rw-r--r--", Encoding: "text", Content: "override"},
+		},
+		{
+			tag:  "deleted",
+			path: "/bin/b",
+			want: OutputReadImageFile{Path: "/bin/a", Layer: base.Digest.String(), Type: "file", Size: 1, Mode: "-rw-r--r--", Encoding: "text", Content: "A"},
+		},
+		{
+			tag:      "deleted",
+			path:     "/bin/a",
+			errorMsg: "file not found: /bin/a",
+		},
+		{
+			tag:  "symlinked",
+			path: "/data/f",
+			want: OutputReadImageFile{Path: "/real/f", Layer: base.Digest.String(), Type: "file", Size: 4, Mode: "-rw-r--r--", Encoding: "text", Content: "real"},
+		},
+		{
+			// the symlink is replaced by a directory in the layer above
+			tag:      "replaced",
+			path:     "/data/f",
+			errorMsg: "file not found: /data/f",
+		},
+		{
+			tag:  "replaced",
+			path: "/data/g",
+			want: OutputReadImageFile{Path: "/data/g", Layer: replaced.Digest.String(), Type: "file", Size: 1, Mode: "-rw-r--r--", Encoding: "text", Content: "g"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag+tt.path, func(t *testing.T) {
+			_, output, err := ReadImageFile(context.Background(), nil, InputReadImageFile{
+				Reference: host + "/test-repo:" + tt.tag,
+				Path:      tt.path,
+			})
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("ReadImageFile() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadImageFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(output, tt.want) {
+				t.Errorf("ReadImageFile() = %+v, want %+v", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadImageFile_WalksLayersOnce(t *testing.T) {
+	reg := newTestRegistry()
+	layers := pushTestFilesystem(reg)
+	fetches := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fetches[path.Base(r.URL.Path)]++
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	// the link target comes after the link in the layer
+	_, output, err := ReadImageFile(context.Background(), nil, InputReadImageFile{
+		Reference: getLocalhostServerURL(ts.URL) + "/test-repo:fs",
+		Path:      "/etc/os-release",
+	})
+	if err != nil {
+		t.Fatalf("ReadImageFile() error = %v", err)
+	}
+	if output.Content != "ID=test\n" {
+		t.Errorf("ReadImageFile() content = %q, want %q", output.Content, "ID=test\n")
+	}
+	for _, layer := range layers {
+		if got := fetches[layer.Digest.String()]; got != 1 {
+			t.Errorf("layer %s fetched %d times, want once", layer.Digest, got)
+		}
+	}
+}
+
+func TestReadImageFile_Error(t *testing.T) {
+	reg := newTestRegistry()
+	layers := pushTestFilesystem(reg)
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	reference := getLocalhostServerURL(ts.URL) + "/test-repo:fs"
+
+	tests := []struct {
+		name     string
+		input    InputReadImageFile
+		errorMsg string
+	}{
+		{
+			name:     "missing path",
+			input:    InputReadImageFile{Reference: reference},
+			errorMsg: "file path is required",
+		},
+		{
+			name:     "deleted by whiteout",
+			input:    InputReadImageFile{Reference: reference, Path: "/etc/motd"},
+			errorMsg: "file not found: /etc/motd",
+		},
+		{
+			name:     "hidden by opaque whiteout",
+			input:    InputReadImageFile{Reference: reference, Path: "/etc/ssl/cert"},
+			errorMsg: "file not found: /etc/ssl/cert",
+		},
+		{
+			name:     "missing file",
+			input:    InputReadImageFile{Reference: reference, Path: "/missing"},
+			errorMsg: "file not found: /missing",
+		},
+		{
+			name:     "link loop",
+			input:    InputReadImageFile{Reference: reference, Path: "/loop/a"},
+			errorMsg: "too many levels of links",
+		},
+		{
+			name:     "deleted in layer",
+			input:    InputReadImageFile{Reference: reference, Path: "/etc/motd", Layer: layers[1].Digest.String()},
+			errorMsg: "/etc/motd is deleted by layer",
+		},
+		{
+			name:     "layer not in image",
+			input:    InputReadImageFile{Reference: reference, Path: "/etc/motd", Layer: "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+			errorMsg: "is not in the image",
+		},
+		{
+			name:     "invalid layer",
+			input:    InputReadImageFile{Reference: reference, Path: "/etc/motd", Layer: "invalid"},
+			errorMsg: "invalid layer digest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadImageFile(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ReadImageFile() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+
+	_, _, err := ReadImageFile(context.Background(), nil, InputReadImageFile{Reference: reference, Path: "/missing"})
+	if !errors.Is(err, errFileNotFound) {
+		t.Errorf("ReadImageFile() error = %v, want %v", err, errFileNotFound)
+	}
+
+	t.Cleanup(func() {
+		Configure(config.Default().Tool)
+	})
+	cfg := config.Default().Tool
+	cfg.MaxBlobSize = 4
+	Configure(cfg)
+	_, _, err = ReadImageFile(context.Background(), nil, InputReadImageFile{Reference: reference, Path: "/etc/hostname"})
+	if err == nil || !strings.Contains(err.Error(), "file too large: 5") {
+		t.Errorf("ReadImageFile() error = %v, want file too large", err)
+	}
+}
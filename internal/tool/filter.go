@@ -0,0 +1,101 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// listFunc lists items page by page after last, as registry.TagLister and
+// registry.Registry do.
+type listFunc func(ctx context.Context, last string, fn func(items []string) error) error
+
+// nameFilter filters names by include and exclude patterns.
+type nameFilter struct {
+	include func(name string) bool
+	exclude func(name string) bool
+}
+
+// newNameFilter creates a filter of the names matching include but not
+// exclude. Empty patterns are skipped.
+func newNameFilter(include, exclude string) (*nameFilter, error) {
+	f := &nameFilter{}
+	var err error
+	if f.include, err = compilePattern(include); err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	if f.exclude, err = compilePattern(exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	return f, nil
+}
+
+// compilePattern compiles a glob pattern, or a regular expression enclosed in
+// slashes, into a matcher. It returns nil for an empty pattern.
+func compilePattern(pattern string) (func(name string) bool, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return func(name string) bool {
+		// the pattern is validated above
+		matched, _ := path.Match(pattern, name)
+		return matched
+	}, nil
+}
+
+// match reports whether the name passes the filter.
+func (f *nameFilter) match(name string) bool {
+	if f.include != nil && !f.include(name) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude(name)
+}
+
+// filterList returns the list function listing the items of list passing the
+// filter.
+func (f *nameFilter) filterList(list listFunc) listFunc {
+	if f.include == nil && f.exclude == nil {
+		return list
+	}
+	return func(ctx context.Context, last string, fn func(items []string) error) error {
+		return list(ctx, last, func(items []string) error {
+			var matched []string
+			for _, item := range items {
+				if f.match(item) {
+					matched = append(matched, item)
+				}
+			}
+			if len(matched) == 0 {
+				return nil
+			}
+			return fn(matched)
+		})
+	}
+}
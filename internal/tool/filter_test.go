@@ -0,0 +1,96 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestNameFilter(t *testing.T) {
+	names := []string{"v1.0", "v1.1-rc.1", "v2.0", "latest", "v1.0-alpine"}
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "no filter",
+			want: names,
+		},
+		{
+			name:    "include glob",
+			include: "v1.*",
+			want:    []string{"v1.0", "v1.1-rc.1", "v1.0-alpine"},
+		},
+		{
+			name:    "include and exclude globs",
+			include: "v1.*",
+			exclude: "*-*",
+			want:    []string{"v1.0"},
+		},
+		{
+			name:    "include regular expression",
+			include: `/^v\d+\.\d+$/`,
+			want:    []string{"v1.0", "v2.0"},
+		},
+		{
+			name:    "exclude regular expression",
+			exclude: "/rc|alpine/",
+			want:    []string{"v1.0", "v2.0", "latest"},
+		},
+		{
+			name:    "invalid glob",
+			include: "v[1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid regular expression",
+			exclude: "/v(1/",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newNameFilter(tt.include, tt.exclude)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newNameFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			list := filter.filterList(func(ctx context.Context, last string, fn func(items []string) error) error {
+				if err := fn(names[:2]); err != nil {
+					return err
+				}
+				return fn(names[2:])
+			})
+			var got []string
+			if err := list(context.Background(), "", func(items []string) error {
+				got = append(got, items...)
+				return nil
+			}); err != nil {
+				t.Fatalf("list() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("list() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/remote"
+	"oras.land/oras-go/v2/content"
+)
+
+// Docker media types of images.
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerConfig       = "application/vnd.docker.container.image.v1+json"
+)
+
+// MetadataFetchImageConfig describes the FetchImageConfig tool.
+var MetadataFetchImageConfig = &mcp.Tool{
+	Name:        "fetch_image_config",
+	Description: "Fetch the config of a container image with a summary of its entrypoint, environment, labels, and history.",
+}
+
+// InputFetchImageConfig is the input for the FetchImageConfig tool.
+type InputFetchImageConfig struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
+	Platform   string `json:"platform,omitempty" jsonschema:"platform of the image, required if the manifest is an index, in the format of os/arch[/variant][:os_version] such as linux/arm64/v8"`
+}
+
+// OutputFetchImageConfig is the output for the FetchImageConfig tool.
+type OutputFetchImageConfig struct {
+	Manifest string             `json:"manifest" jsonschema:"digest of the image manifest"`
+	Platform string             `json:"platform,omitempty" jsonschema:"platform of the image in the format of os/arch[/variant][:os_version]"`
+	Summary  ImageConfigSummary `json:"summary" jsonschema:"summary of the image config"`
+	Config   map[string]any     `json:"config" jsonschema:"image config in JSON format"`
+}
+
+// ImageConfigSummary is the summary of an image config.
+type ImageConfigSummary struct {
+	Created      string            `json:"created,omitempty" jsonschema:"creation time of the image"`
+	Entrypoint   []string          `json:"entrypoint,omitempty" jsonschema:"entrypoint of the container"`
+	Cmd          []string          `json:"cmd,omitempty" jsonschema:"default arguments to the entrypoint"`
+	Env          []string          `json:"env,omitempty" jsonschema:"environment variables in the format of NAME=VALUE"`
+	User         string            `json:"user,omitempty" jsonschema:"user running the container"`
+	WorkingDir   string            `json:"workingDir,omitempty" jsonschema:"working directory of the container"`
+	ExposedPorts []string          `json:"exposedPorts,omitempty" jsonschema:"exposed ports in the format of port/protocol"`
+	Labels       map[string]string `json:"labels,omitempty" jsonschema:"labels of the image"`
+	History      []ImageHistory    `json:"history,omitempty" jsonschema:"history of the image, from the base layer up"`
+}
+
+// ImageHistory is an entry of the history of an image.
+type ImageHistory struct {
+	Created    string `json:"created,omitempty" jsonschema:"creation time of the entry"`
+	CreatedBy  string `json:"createdBy,omitempty" jsonschema:"instruction creating the entry"`
+	Comment    string `json:"comment,omitempty" jsonschema:"comment of the entry"`
+	EmptyLayer bool   `json:"emptyLayer,omitempty" jsonschema:"whether the entry creates no layer"`
+}
+
+// FetchImageConfig fetches the config of a container image.
+func FetchImageConfig(ctx context.Context, _ *mcp.CallToolRequest, input InputFetchImageConfig) (*mcp.CallToolResult, OutputFetchImageConfig, error) {
+	// validate input
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputFetchImageConfig{}, err
+	}
+	if ref.Reference == "" {
+		return nil, OutputFetchImageConfig{}, fmt.Errorf("either tag or digest is required")
+	}
+	var platform *ocispec.Platform
+	if input.Platform != "" {
+		if platform, err = parsePlatform(input.Platform); err != nil {
+			return nil, OutputFetchImageConfig{}, err
+		}
+	}
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
+
+	// follow the manifest to the config
+	desc, manifest, err := resolveImage(ctx, repo, ref.Reference, platform)
+	if err != nil {
+		return nil, OutputFetchImageConfig{}, err
+	}
+	config, configBytes, err := fetchImageConfig(ctx, repo, manifest.Config)
+	if err != nil {
+		return nil, OutputFetchImageConfig{}, err
+	}
+	var configJSON map[string]any
+	if err := json.Unmarshal(configBytes, &configJSON); err != nil {
+		return nil, OutputFetchImageConfig{}, fmt.Errorf("failed to parse image config %s: %w", manifest.Config.Digest, err)
+	}
+
+	output := OutputFetchImageConfig{
+		Manifest: desc.Digest.String(),
+		Platform: formatPlatform(&config.Platform),
+		Summary:  summarizeImageConfig(config),
+		Config:   configJSON,
+	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
+
+// summarizeImageConfig returns the summary of the image config.
+func summarizeImageConfig(config ocispec.Image) ImageConfigSummary {
+	summary := ImageConfigSummary{
+		Created:    formatTime(config.Created),
+		Entrypoint: config.Config.Entrypoint,
+		Cmd:        config.Config.Cmd,
+		Env:        config.Config.Env,
+		User:       config.Config.User,
+		WorkingDir: config.Config.WorkingDir,
+		Labels:     config.Config.Labels,
+	}
+	for port := range config.Config.ExposedPorts {
+		summary.ExposedPorts = append(summary.ExposedPorts, port)
+	}
+	slices.Sort(summary.ExposedPorts)
+	for _, history := range config.History {
+		summary.History = append(summary.History, ImageHistory{
+			Created:    formatTime(history.Created),
+			CreatedBy:  history.CreatedBy,
+			Comment:    history.Comment,
+			EmptyLayer: history.EmptyLayer,
+		})
+	}
+	return summary
+}
+
+// formatTime formats the time in RFC 3339, or returns an empty string if the
+// time is unset.
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// isIndex reports whether the media type is of an image index.
+func isIndex(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageIndex || mediaType == mediaTypeDockerManifestList
+}
+
+// isImageConfig reports whether the media type is of an image config.
+func isImageConfig(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageConfig || mediaType == mediaTypeDockerConfig
+}
+
+// fetchManifest fetches the manifest identified by the reference.
+func fetchManifest(ctx context.Context, repo *remote.Repository, reference string) (ocispec.Descriptor, []byte, error) {
+	desc, rc, err := repo.FetchReference(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	defer rc.Close()
+	manifest, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, manifest, nil
+}
+
+// fetchManifestContent fetches the content of the manifest described by desc.
+func fetchManifestContent(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) ([]byte, error) {
+	rc, err := repo.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return content.ReadAll(rc, desc)
+}
+
+// resolveImage fetches the image manifest identified by the reference. An
+// index is resolved to the manifest of the platform, which is required, and an
+// image manifest is checked against the platform if given.
+func resolveImage(ctx context.Context, repo *remote.Repository, reference string, platform *ocispec.Platform) (ocispec.Descriptor, ocispec.Manifest, error) {
+	desc, manifestBytes, err := fetchManifest(ctx, repo, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, ocispec.Manifest{}, err
+	}
+	if platform != nil {
+		desc, manifestBytes, err = selectPlatform(ctx, repo, desc, manifestBytes, platform)
+		if err != nil {
+			return ocispec.Descriptor{}, ocispec.Manifest{}, err
+		}
+	} else if isIndex(desc.MediaType) {
+		var index ocispec.Index
+		if err := json.Unmarshal(manifestBytes, &index); err != nil {
+			return ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("failed to parse index %s: %w", desc.Digest, err)
+		}
+		var available []string
+		for _, child := range index.Manifests {
+			if child.Platform != nil && !isAttestation(child) {
+				available = append(available, formatPlatform(child.Platform))
+			}
+		}
+		return ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("platform is required as manifest %s is an index, available platforms: %s", desc.Digest, strings.Join(available, ", "))
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+	}
+	if !isImageConfig(manifest.Config.MediaType) {
+		return ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("manifest %s of media type %s is not a container image", desc.Digest, desc.MediaType)
+	}
+	return desc, manifest, nil
+}
+
+// selectPlatform selects the image manifest of the platform from the manifest
+// described by desc. An index is resolved to its first child matching the
+// platform, and an image manifest is checked against the platform of its
+// config.
+func selectPlatform(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, manifest []byte, platform *ocispec.Platform) (ocispec.Descriptor, []byte, error) {
+	if !isIndex(desc.MediaType) {
+		imagePlatform, err := fetchImagePlatform(ctx, repo, desc, manifest)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		if !matchPlatform(imagePlatform, platform) {
+			return ocispec.Descriptor{}, nil, fmt.Errorf("image %s is of platform %s instead of %s", desc.Digest, formatPlatform(imagePlatform), formatPlatform(platform))
+		}
+		desc.Platform = imagePlatform
+		return desc, manifest, nil
+	}
+
+	var index ocispec.Index
+	if err := json.Unmarshal(manifest, &index); err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to parse index %s: %w", desc.Digest, err)
+	}
+	var available []string
+	for _, child := range index.Manifests {
+		if !matchPlatform(child.Platform, platform) {
+			if child.Platform != nil {
+				available = append(available, formatPlatform(child.Platform))
+			}
+			continue
+		}
+		childManifest, err := fetchManifestContent(ctx, repo, child)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		if isIndex(child.MediaType) {
+			return selectPlatform(ctx, repo, child, childManifest, platform)
+		}
+		return child, childManifest, nil
+	}
+	if len(available) == 0 {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("no manifest of platform %s in index %s, which declares no platforms", formatPlatform(platform), desc.Digest)
+	}
+	return ocispec.Descriptor{}, nil, fmt.Errorf("no manifest of platform %s in index %s, available platforms: %s", formatPlatform(platform), desc.Digest, strings.Join(available, ", "))
+}
+
+// fetchImagePlatform returns the platform of the image manifest described by
+// desc, as recorded in its config.
+func fetchImagePlatform(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, manifest []byte) (*ocispec.Platform, error) {
+	var image ocispec.Manifest
+	if err := json.Unmarshal(manifest, &image); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+	}
+	if !isImageConfig(image.Config.MediaType) {
+		return nil, fmt.Errorf("manifest %s of media type %s is neither an image index nor a container image", desc.Digest, desc.MediaType)
+	}
+	config, _, err := fetchImageConfig(ctx, repo, image.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &config.Platform, nil
+}
+
+// fetchImageConfig fetches the image config described by desc, returning it
+// both parsed and as is.
+func fetchImageConfig(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) (ocispec.Image, []byte, error) {
+	if desc.Size > maxBlobSize {
+		return ocispec.Image{}, nil, fmt.Errorf("config too large: %d", desc.Size)
+	}
+	rc, err := repo.Blobs().Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Image{}, nil, err
+	}
+	defer rc.Close()
+	configBytes, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return ocispec.Image{}, nil, err
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return ocispec.Image{}, nil, fmt.Errorf("failed to parse image config %s: %w", desc.Digest, err)
+	}
+	return config, configBytes, nil
+}
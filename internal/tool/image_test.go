@@ -0,0 +1,144 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestFetchImageConfig(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	config := ocispec.Image{
+		Created:  &created,
+		Platform: ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		Config: ocispec.ImageConfig{
+			User:         "nobody",
+			ExposedPorts: map[string]struct{}{"8080/tcp": {}, "443/tcp": {}},
+			Env:          []string{"PATH=/usr/bin"},
+			Entrypoint:   []string{"/app"},
+			Cmd:          []string{"--help"},
+			WorkingDir:   "/work",
+			Labels:       map[string]string{"version": "1.0"},
+		},
+		History: []ocispec.History{
+			{Created: &created, CreatedBy: "ADD rootfs.tar /"},
+			{CreatedBy: "ENV PATH=/usr/bin", EmptyLayer: true},
+		},
+	}
+	reg := newTestRegistry()
+	amd64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}})
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := reg.pushImage(config)
+	arm64.Platform = &config.Platform
+	reg.pushIndex("multi", amd64, arm64)
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	_, output, err := FetchImageConfig(context.Background(), nil, InputFetchImageConfig{
+		Reference: host + "/test-repo:multi",
+		Platform:  "linux/arm64",
+	})
+	if err != nil {
+		t.Fatalf("FetchImageConfig() error = %v", err)
+	}
+	if output.Manifest != arm64.Digest.String() {
+		t.Errorf("manifest = %s, want %s", output.Manifest, arm64.Digest)
+	}
+	if output.Platform != "linux/arm64/v8" {
+		t.Errorf("platform = %s, want linux/arm64/v8", output.Platform)
+	}
+	wantSummary := ImageConfigSummary{
+		Created:      "2025-01-02T03:04:05Z",
+		Entrypoint:   []string{"/app"},
+		Cmd:          []string{"--help"},
+		Env:          []string{"PATH=/usr/bin"},
+		User:         "nobody",
+		WorkingDir:   "/work",
+		ExposedPorts: []string{"443/tcp", "8080/tcp"},
+		Labels:       map[string]string{"version": "1.0"},
+		History: []ImageHistory{
+			{Created: "2025-01-02T03:04:05Z", CreatedBy: "ADD rootfs.tar /"},
+			{CreatedBy: "ENV PATH=/usr/bin", EmptyLayer: true},
+		},
+	}
+	if !reflect.DeepEqual(output.Summary, wantSummary) {
+		t.Errorf("summary = %+v, want %+v", output.Summary, wantSummary)
+	}
+	if got := output.Config["architecture"]; got != "arm64" {
+		t.Errorf("config architecture = %v, want arm64", got)
+	}
+}
+
+func TestFetchImageConfig_Error(t *testing.T) {
+	reg := newTestRegistry()
+	amd64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}})
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	reg.pushIndex("multi", amd64)
+	reg.pushManifest(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.example",
+		Config:       reg.pushBlob(ocispec.MediaTypeEmptyJSON, []byte("{}")),
+		Layers:       []ocispec.Descriptor{},
+	}, "artifact")
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	tests := []struct {
+		name     string
+		input    InputFetchImageConfig
+		errorMsg string
+	}{
+		{
+			name:     "missing reference",
+			input:    InputFetchImageConfig{},
+			errorMsg: "required",
+		},
+		{
+			name:     "invalid platform",
+			input:    InputFetchImageConfig{Reference: host + "/test-repo:multi", Platform: "linux"},
+			errorMsg: "invalid platform",
+		},
+		{
+			name:     "index without platform",
+			input:    InputFetchImageConfig{Reference: host + "/test-repo:multi"},
+			errorMsg: "platform is required as manifest " + reg.manifests["multi"].Digest.String() + " is an index, available platforms: linux/amd64",
+		},
+		{
+			name:     "artifact",
+			input:    InputFetchImageConfig{Reference: host + "/test-repo:artifact"},
+			errorMsg: "is not a container image",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := FetchImageConfig(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("FetchImageConfig() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// MetadataInspectLayers describes the InspectLayers tool.
+var MetadataInspectLayers = &mcp.Tool{
+	Name:        "inspect_layers",
+	Description: "Inspect the layers of a container image with their sizes and the instructions creating them, without downloading the layers. Each platform of a multi-platform image is inspected unless a platform is given.",
+}
+
+// InputInspectLayers is the input for the InspectLayers tool.
+type InputInspectLayers struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
+	Platform   string `json:"platform,omitempty" jsonschema:"platform of the image to inspect if the manifest is an index, in the format of os/arch[/variant][:os_version] such as linux/arm64/v8"`
+}
+
+// OutputInspectLayers is the output for the InspectLayers tool.
+type OutputInspectLayers struct {
+	Images    []ImageLayers `json:"images" jsonschema:"inspected images, one for each platform"`
+	TotalSize int64         `json:"totalSize" jsonschema:"total size in bytes of the distinct manifests, configs, and layers of the images"`
+}
+
+// ImageLayers is the layer breakdown of an image.
+type ImageLayers struct {
+	Manifest   string      `json:"manifest" jsonschema:"digest of the image manifest"`
+	Platform   string      `json:"platform,omitempty" jsonschema:"platform of the image in the format of os/arch[/variant][:os_version]"`
+	Layers     []LayerInfo `json:"layers" jsonschema:"layers of the image, from the base layer up"`
+	LayersSize int64       `json:"layersSize" jsonschema:"total compressed size in bytes of the layers"`
+	TotalSize  int64       `json:"totalSize" jsonschema:"total size in bytes of the manifest, the config, and the layers"`
+}
+
+// LayerInfo describes a layer of an image.
+type LayerInfo struct {
+	Digest    string  `json:"digest" jsonschema:"layer digest"`
+	MediaType string  `json:"mediaType" jsonschema:"media type of the layer"`
+	Size      int64   `json:"size" jsonschema:"compressed size of the layer in bytes"`
+	Share     float64 `json:"share" jsonschema:"percentage of the layer in the total compressed size of the layers"`
+	Created   string  `json:"created,omitempty" jsonschema:"creation time of the layer, from the config history"`
+	CreatedBy string  `json:"createdBy,omitempty" jsonschema:"instruction creating the layer, from the config history"`
+	Comment   string  `json:"comment,omitempty" jsonschema:"comment of the layer, from the config history"`
+}
+
+// InspectLayers inspects the layers of a container image.
+func InspectLayers(ctx context.Context, _ *mcp.CallToolRequest, input InputInspectLayers) (*mcp.CallToolResult, OutputInspectLayers, error) {
+	// validate input
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputInspectLayers{}, err
+	}
+	if ref.Reference == "" {
+		return nil, OutputInspectLayers{}, fmt.Errorf("either tag or digest is required")
+	}
+	var platform *ocispec.Platform
+	if input.Platform != "" {
+		if platform, err = parsePlatform(input.Platform); err != nil {
+			return nil, OutputInspectLayers{}, err
+		}
+	}
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
+
+	// collect the image manifests to inspect
+	desc, manifestBytes, err := fetchManifest(ctx, repo, ref.Reference)
+	if err != nil {
+		return nil, OutputInspectLayers{}, err
+	}
+	type target struct {
+		desc     ocispec.Descriptor
+		manifest []byte
+	}
+	var targets []target
+	switch {
+	case platform != nil:
+		desc, manifestBytes, err = selectPlatform(ctx, repo, desc, manifestBytes, platform)
+		if err != nil {
+			return nil, OutputInspectLayers{}, err
+		}
+		targets = append(targets, target{desc: desc, manifest: manifestBytes})
+	case isIndex(desc.MediaType):
+		var index ocispec.Index
+		if err := json.Unmarshal(manifestBytes, &index); err != nil {
+			return nil, OutputInspectLayers{}, fmt.Errorf("failed to parse index %s: %w", desc.Digest, err)
+		}
+		for _, child := range index.Manifests {
+			if isIndex(child.MediaType) || isAttestation(child) {
+				continue
+			}
+			childManifest, err := fetchManifestContent(ctx, repo, child)
+			if err != nil {
+				return nil, OutputInspectLayers{}, err
+			}
+			targets = append(targets, target{desc: child, manifest: childManifest})
+		}
+	default:
+		targets = append(targets, target{desc: desc, manifest: manifestBytes})
+	}
+
+	// inspect the images
+	output := OutputInspectLayers{
+		Images: make([]ImageLayers, 0, len(targets)),
+	}
+	counted := make(map[string]bool)
+	count := func(desc ocispec.Descriptor) {
+		if !counted[desc.Digest.String()] {
+			counted[desc.Digest.String()] = true
+			output.TotalSize += desc.Size
+		}
+	}
+	for _, target := range targets {
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(target.manifest, &manifest); err != nil {
+			return nil, OutputInspectLayers{}, fmt.Errorf("failed to parse manifest %s: %w", target.desc.Digest, err)
+		}
+		if !isImageConfig(manifest.Config.MediaType) {
+			return nil, OutputInspectLayers{}, fmt.Errorf("manifest %s of media type %s is not a container image", target.desc.Digest, target.desc.MediaType)
+		}
+		config, _, err := fetchImageConfig(ctx, repo, manifest.Config)
+		if err != nil {
+			return nil, OutputInspectLayers{}, err
+		}
+		output.Images = append(output.Images, inspectImageLayers(target.desc, manifest, config))
+		count(target.desc)
+		count(manifest.Config)
+		for _, layer := range manifest.Layers {
+			count(layer)
+		}
+	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
+
+// inspectImageLayers returns the layer breakdown of the image, joining the
+// layers with the history entries creating them.
+func inspectImageLayers(desc ocispec.Descriptor, manifest ocispec.Manifest, config ocispec.Image) ImageLayers {
+	image := ImageLayers{
+		Manifest: desc.Digest.String(),
+		Platform: formatPlatform(&config.Platform),
+		Layers:   make([]LayerInfo, 0, len(manifest.Layers)),
+	}
+	for _, layer := range manifest.Layers {
+		image.LayersSize += layer.Size
+	}
+	image.TotalSize = desc.Size + manifest.Config.Size + image.LayersSize
+
+	// empty layers have history entries but no layers
+	var history []ocispec.History
+	for _, entry := range config.History {
+		if !entry.EmptyLayer {
+			history = append(history, entry)
+		}
+	}
+	for i, layer := range manifest.Layers {
+		info := LayerInfo{
+			Digest:    layer.Digest.String(),
+			MediaType: layer.MediaType,
+			Size:      layer.Size,
+		}
+		if image.LayersSize > 0 {
+			info.Share = math.Round(float64(layer.Size)*10000/float64(image.LayersSize)) / 100
+		}
+		if i < len(history) {
+			info.Created = formatTime(history[i].Created)
+			info.CreatedBy = history[i].CreatedBy
+			info.Comment = history[i].Comment
+		}
+		image.Layers = append(image.Layers, info)
+	}
+	return image
+}
+
+// Entry limits of the ListLayerFiles tool.
+const (
+	defaultEntryLimit = 1000
+	maxEntryLimit     = 10000
+)
+
+// MetadataListLayerFiles describes the ListLayerFiles tool.
+var MetadataListLayerFiles = &mcp.Tool{
+	Name:        "list_layer_files",
+	Description: "List the files in a layer of a container image, including the whiteouts deleting files of the layers below.",
+}
+
+// InputListLayerFiles is the input for the ListLayerFiles tool.
+type InputListLayerFiles struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string with the layer digest, as an alternative to registry, repository, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"layer digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx"`
+	Prefix     string `json:"prefix,omitempty" jsonschema:"list only the entries at or under this path, such as /etc"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"maximum number of entries to list, 1000 by default and at most 10000"`
+}
+
+// OutputListLayerFiles is the output for the ListLayerFiles tool.
+type OutputListLayerFiles struct {
+	Files     []LayerFile `json:"files" jsonschema:"entries of the layer in the archive order"`
+	Truncated bool        `json:"truncated,omitempty" jsonschema:"whether more entries are left unlisted due to the limit"`
+}
+
+// LayerFile is an entry of a layer.
+type LayerFile struct {
+	Path       string `json:"path" jsonschema:"absolute path of the entry, or of the deleted file if it is a whiteout"`
+	Type       string `json:"type" jsonschema:"entry type, one of file, dir, symlink, hardlink, char, block, fifo, other, whiteout (deleting the path), and opaque_whiteout (deleting the contents of the directory from the layers below)"`
+	Size       int64  `json:"size,omitempty" jsonschema:"file size in bytes"`
+	Mode       string `json:"mode,omitempty" jsonschema:"file mode and permission bits, such as -rwxr-xr-x"`
+	LinkTarget string `json:"linkTarget,omitempty" jsonschema:"target of the symlink or the hardlink"`
+}
+
+// ListLayerFiles lists the files in a layer of a container image.
+func ListLayerFiles(ctx context.Context, _ *mcp.CallToolRequest, input InputListLayerFiles) (*mcp.CallToolResult, OutputListLayerFiles, error) {
+	// validate input
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, "", input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputListLayerFiles{}, err
+	}
+	if ref.ValidateReferenceAsDigest() != nil {
+		return nil, OutputListLayerFiles{}, fmt.Errorf("layer digest is required")
+	}
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultEntryLimit
+	}
+	if limit < 0 || limit > maxEntryLimit {
+		return nil, OutputListLayerFiles{}, fmt.Errorf("limit must be between 1 and %d", maxEntryLimit)
+	}
+	prefix := layerPath(input.Prefix)
+	repo := remote.NewRepository(ctx, ref)
+
+	// stream the layer
+	desc, rc, err := repo.Blobs().FetchReference(ctx, ref.Reference)
+	if err != nil {
+		return nil, OutputListLayerFiles{}, err
+	}
+	defer rc.Close()
+	output := OutputListLayerFiles{
+		Files: []LayerFile{},
+	}
+	if err := walkArchive(desc, rc, func(hdr *tar.Header, _ io.Reader) error {
+		file := layerFile(hdr)
+		if !underPath(file.Path, prefix) {
+			return nil
+		}
+		if len(output.Files) == limit {
+			output.Truncated = true
+			return errWalkDone
+		}
+		output.Files = append(output.Files, file)
+		return nil
+	}); err != nil {
+		return nil, OutputListLayerFiles{}, err
+	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
+
+// layerFile returns the layer entry described by the header.
+func layerFile(hdr *tar.Header) LayerFile {
+	p := layerPath(hdr.Name)
+	dir, base := path.Split(p)
+	switch {
+	case base == whiteoutOpaque:
+		return LayerFile{Path: layerPath(dir), Type: entryOpaqueWhiteout}
+	case strings.HasPrefix(base, whiteoutPrefix):
+		return LayerFile{Path: path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), Type: entryWhiteout}
+	}
+	file := LayerFile{
+		Path:       p,
+		Type:       entryType(hdr.Typeflag),
+		Size:       hdr.Size,
+		Mode:       hdr.FileInfo().Mode().String(),
+		LinkTarget: hdr.Linkname,
+	}
+	if hdr.Typeflag == tar.TypeLink {
+		// hardlinks target the paths in the layer
+		file.LinkTarget = layerPath(hdr.Linkname)
+	}
+	return file
+}
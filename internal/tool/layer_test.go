@@ -0,0 +1,214 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestInspectLayers(t *testing.T) {
+	reg := newTestRegistry()
+	base := reg.pushBlob(ocispec.MediaTypeImageLayerGzip, []byte(strings.Repeat("b", 300)))
+	app := reg.pushBlob(ocispec.MediaTypeImageLayerGzip, []byte(strings.Repeat("a", 100)))
+	amd64Config := ocispec.Image{
+		Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		History: []ocispec.History{
+			{CreatedBy: "ADD rootfs.tar /"},
+			{CreatedBy: "ENV APP=1", EmptyLayer: true},
+			{CreatedBy: "COPY app /app", Comment: "buildkit"},
+		},
+	}
+	amd64 := reg.pushImage(amd64Config, base, app)
+	amd64.Platform = &amd64Config.Platform
+	arm64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "arm64"}}, base)
+	arm64.Platform = &ocispec.Platform{OS: "linux", Architecture: "arm64"}
+	attestation := reg.pushImage(ocispec.Image{})
+	attestation.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+	index := reg.pushIndex("multi", amd64, arm64, attestation)
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	amd64Layers := ImageLayers{
+		Manifest: amd64.Digest.String(),
+		Platform: "linux/amd64",
+		Layers: []LayerInfo{
+			{Digest: base.Digest.String(), MediaType: base.MediaType, Size: 300, Share: 75, CreatedBy: "ADD rootfs.tar /"},
+			{Digest: app.Digest.String(), MediaType: app.MediaType, Size: 100, Share: 25, CreatedBy: "COPY app /app", Comment: "buildkit"},
+		},
+		LayersSize: 400,
+		TotalSize:  amd64.Size + reg.configSize(amd64) + 400,
+	}
+	arm64Layers := ImageLayers{
+		Manifest: arm64.Digest.String(),
+		Platform: "linux/arm64",
+		Layers: []LayerInfo{
+			{Digest: base.Digest.String(), MediaType: base.MediaType, Size: 300, Share: 100},
+		},
+		LayersSize: 300,
+		TotalSize:  arm64.Size + reg.configSize(arm64) + 300,
+	}
+
+	ctx := context.Background()
+	_, output, err := InspectLayers(ctx, nil, InputInspectLayers{Reference: host + "/test-repo:multi"})
+	if err != nil {
+		t.Fatalf("InspectLayers() error = %v", err)
+	}
+	want := OutputInspectLayers{
+		Images: []ImageLayers{amd64Layers, arm64Layers},
+		// the shared base layer is counted once
+		TotalSize: amd64Layers.TotalSize + arm64Layers.TotalSize - 300,
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("InspectLayers() = %+v, want %+v", output, want)
+	}
+
+	_, output, err = InspectLayers(ctx, nil, InputInspectLayers{Reference: host + "/test-repo@" + index.Digest.String(), Platform: "linux/arm64"})
+	if err != nil {
+		t.Fatalf("InspectLayers() error = %v", err)
+	}
+	want = OutputInspectLayers{
+		Images:    []ImageLayers{arm64Layers},
+		TotalSize: arm64Layers.TotalSize,
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("InspectLayers() = %+v, want %+v", output, want)
+	}
+}
+
+func TestInspectLayers_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    InputInspectLayers
+		errorMsg string
+	}{
+		{
+			name:     "empty input",
+			input:    InputInspectLayers{},
+			errorMsg: "required",
+		},
+		{
+			name:     "reference without tag",
+			input:    InputInspectLayers{Reference: "localhost:5000/test-repo"},
+			errorMsg: "either tag or digest is required",
+		},
+		{
+			name:     "invalid platform",
+			input:    InputInspectLayers{Reference: "localhost:5000/test-repo:v1", Platform: "linux/"},
+			errorMsg: "invalid platform",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := InspectLayers(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("InspectLayers() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestListLayerFiles(t *testing.T) {
+	reg := newTestRegistry()
+	layer := reg.pushBlob(ocispec.MediaTypeImageLayerGzip, gzipLayer(buildLayer(
+		testEntry{name: "./etc/"},
+		testEntry{name: "./etc/hostname", content: "test"},
+		testEntry{name: "./etc/.wh.motd"},
+		testEntry{name: "./etc/ssl/.wh..wh..opq"},
+		testEntry{name: "./etcetera"},
+		testEntry{name: "./bin/sh", link: "busybox"},
+	)))
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	reference := getLocalhostServerURL(ts.URL) + "/test-repo@" + layer.Digest.String()
+
+	ctx := context.Background()
+	_, output, err := ListLayerFiles(ctx, nil, InputListLayerFiles{Reference: reference, Prefix: "/etc"})
+	if err != nil {
+		t.Fatalf("ListLayerFiles() error = %v", err)
+	}
+	want := OutputListLayerFiles{
+		Files: []LayerFile{
+			{Path: "/etc", Type: "dir", Mode: "drwxr-xr-x"},
+			{Path: "/etc/hostname", Type: "file", Size: 4, Mode: "-rw-r--r--"},
+			{Path: "/etc/motd", Type: "whiteout"},
+			{Path: "/etc/ssl", Type: "opaque_whiteout"},
+		},
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("ListLayerFiles() = %+v, want %+v", output, want)
+	}
+
+	_, output, err = ListLayerFiles(ctx, nil, InputListLayerFiles{Reference: reference, Limit: 5})
+	if err != nil {
+		t.Fatalf("ListLayerFiles() error = %v", err)
+	}
+	if len(output.Files) != 5 || !output.Truncated {
+		t.Errorf("ListLayerFiles() listed %d files with truncated %v, want 5 files truncated", len(output.Files), output.Truncated)
+	}
+
+	_, output, err = ListLayerFiles(ctx, nil, InputListLayerFiles{Reference: reference, Prefix: "bin"})
+	if err != nil {
+		t.Fatalf("ListLayerFiles() error = %v", err)
+	}
+	want = OutputListLayerFiles{
+		Files: []LayerFile{
+			{Path: "/bin/sh", Type: "symlink", Mode: "Lrwxrwxrwx", LinkTarget: "busybox"},
+		},
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("ListLayerFiles() = %+v, want %+v", output, want)
+	}
+}
+
+func TestListLayerFiles_InvalidInput(t *testing.T) {
+	const validDigest = "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	tests := []struct {
+		name     string
+		input    InputListLayerFiles
+		errorMsg string
+	}{
+		{
+			name:     "empty input",
+			input:    InputListLayerFiles{},
+			errorMsg: "required",
+		},
+		{
+			name:     "reference without digest",
+			input:    InputListLayerFiles{Reference: "localhost:5000/test-repo:v1"},
+			errorMsg: "layer digest is required",
+		},
+		{
+			name:     "limit too large",
+			input:    InputListLayerFiles{Reference: "localhost:5000/test-repo@" + validDigest, Limit: 10001},
+			errorMsg: "limit must be between 1 and 10000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ListLayerFiles(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ListLayerFiles() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -22,9 +22,8 @@ import (
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/oras-project/oras-mcp/internal/remote"
-	"oras.land/oras-go/v2/content"
-	"oras.land/oras-go/v2/registry"
 )
 
 // MetadataFetchManifest describes the FetchManifest tool.
@@ -40,10 +39,14 @@ var MetadataFetchManifest = &mcp.Tool{
 
 // InputFetchManifest is the input for the FetchManifest tool.
 type InputFetchManifest struct {
-	Registry   string `json:"registry" jsonschema:"registry name"`
-	Repository string `json:"repository" jsonschema:"repository name"`
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
 	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
 	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
+	Platform   string `json:"platform,omitempty" jsonschema:"platform of the image manifest to select if the manifest is an index, in the format of os/arch[/variant][:os_version] such as linux/arm64/v8"`
 }
 
 // OutputFetchManifest is the output for the FetchManifest tool.
@@ -69,40 +72,49 @@ func (o OutputFetchManifest) Raw() []byte {
 // FetchManifest fetches manifest of a container image or an OCI artifact.
 func FetchManifest(ctx context.Context, _ *mcp.CallToolRequest, input InputFetchManifest) (*mcp.CallToolResult, OutputFetchManifest, error) {
 	// validate input
-	if input.Registry == "" || input.Repository == "" {
-		return nil, OutputFetchManifest{}, fmt.Errorf("registry and repository names are required")
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputFetchManifest{}, err
 	}
-	if input.Tag == "" && input.Digest == "" {
+	if ref.Reference == "" {
 		return nil, OutputFetchManifest{}, fmt.Errorf("either tag or digest is required")
 	}
-	ref := registry.Reference{
-		Registry:   input.Registry,
-		Repository: input.Repository,
-		Reference:  input.Tag,
-	}
-	if input.Digest != "" {
-		ref.Reference = input.Digest
-	}
-	if err := ref.Validate(); err != nil {
-		return nil, OutputFetchManifest{}, err
+	var platform *ocispec.Platform
+	if input.Platform != "" {
+		if platform, err = parsePlatform(input.Platform); err != nil {
+			return nil, OutputFetchManifest{}, err
+		}
 	}
-	repo := remote.NewRepository(ref)
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
 
 	// fetch the manifest
-	desc, rc, err := repo.FetchReference(ctx, ref.Reference)
+	desc, manifestBytes, err := fetchManifest(ctx, repo, ref.Reference)
 	if err != nil {
 		return nil, OutputFetchManifest{}, err
 	}
-	defer rc.Close()
-
-	manifestBytes, err := content.ReadAll(rc, desc)
-	if err != nil {
-		return nil, OutputFetchManifest{}, err
+	var notes []resultNote
+	if platform != nil {
+		selected, selectedBytes, err := selectPlatform(ctx, repo, desc, manifestBytes, platform)
+		if err != nil {
+			return nil, OutputFetchManifest{}, err
+		}
+		text := fmt.Sprintf("Manifest %s is of platform %s.", selected.Digest, formatPlatform(selected.Platform))
+		if selected.Digest != desc.Digest {
+			text = fmt.Sprintf("Selected manifest %s of platform %s from index %s.", selected.Digest, formatPlatform(selected.Platform), desc.Digest)
+		}
+		notes = append(notes, resultNote{
+			key:   "descriptor",
+			value: selected,
+			text:  text,
+		})
+		manifestBytes = selectedBytes
 	}
 
 	// output direct as manifests are already in JSON
 	output := OutputFetchManifest{
 		manifest: json.RawMessage(manifestBytes),
 	}
-	return nil, output, nil
+	result, err := repositoryResult(repo, original, output, notes...)
+	return result, output, err
 }
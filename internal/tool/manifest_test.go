@@ -23,10 +23,13 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func TestFetchManifest_OutputSchema(t *testing.T) {
@@ -123,6 +126,94 @@ func TestFetchManifest_SuccessWithTag(t *testing.T) {
 	}
 }
 
+func TestFetchManifest_SuccessWithReference(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	dgst := digest.FromBytes(manifest)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/test-repo/manifests/v1" {
+			t.Fatalf("unexpected path accessed: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.Write(manifest)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	input := InputFetchManifest{
+		Reference: getLocalhostServerURL(ts.URL) + "/test-repo:v1",
+	}
+
+	_, output, err := FetchManifest(ctx, nil, input)
+	if err != nil {
+		t.Fatalf("FetchManifest() error = %v", err)
+	}
+	if !bytes.Equal(output.Raw(), manifest) {
+		t.Fatalf("unexpected manifest data: got %s, want %s", string(output.Raw()), string(manifest))
+	}
+}
+
+func TestFetchManifest_ConflictError(t *testing.T) {
+	input := InputFetchManifest{
+		Reference:  "localhost:5000/repo:latest",
+		Registry:   "localhost:5000",
+		Repository: "repo",
+	}
+	_, _, err := FetchManifest(context.Background(), nil, input)
+	if err == nil {
+		t.Fatal("FetchManifest() error = nil, want error")
+	}
+	if want := "reference conflicts with registry, repository:"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("FetchManifest() error = %v, want error containing %q", err, want)
+	}
+}
+
+func TestFetchManifest_Refresh(t *testing.T) {
+	manifests := [][]byte{
+		[]byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","annotations":{"version":"1"}}`),
+		[]byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","annotations":{"version":"2"}}`),
+	}
+	var current atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		manifest := manifests[current.Load()]
+		for _, m := range manifests {
+			if r.URL.Path == "/v2/test-repo/manifests/"+digest.FromBytes(m).String() {
+				manifest = m
+			}
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+		w.Write(manifest)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	input := InputFetchManifest{
+		Registry:   getLocalhostServerURL(ts.URL),
+		Repository: "test-repo",
+		Tag:        "latest",
+	}
+	fetch := func(refresh bool, want []byte) {
+		t.Helper()
+		input.Refresh = refresh
+		_, output, err := FetchManifest(ctx, nil, input)
+		if err != nil {
+			t.Fatalf("FetchManifest() error = %v", err)
+		}
+		if !bytes.Equal(output.Raw(), want) {
+			t.Fatalf("unexpected manifest data: got %s, want %s", output.Raw(), want)
+		}
+	}
+
+	fetch(false, manifests[0])
+	// the tag is moved, but resolved by the tag cache
+	current.Store(1)
+	fetch(false, manifests[0])
+	fetch(true, manifests[1])
+	fetch(false, manifests[1])
+}
+
 func TestFetchManifest_SuccessWithDigest(t *testing.T) {
 	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
 	dgst := digest.FromBytes(manifest)
@@ -169,6 +260,70 @@ func TestFetchManifest_SuccessWithDigest(t *testing.T) {
 	}
 }
 
+func TestFetchManifest_Platform(t *testing.T) {
+	reg := newTestRegistry()
+	amd64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}})
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}})
+	arm64.Platform = &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	index := reg.pushIndex("multi", amd64, arm64)
+	reg.manifests["single"] = amd64
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	ctx := context.Background()
+	result, output, err := FetchManifest(ctx, nil, InputFetchManifest{
+		Reference: host + "/test-repo:multi",
+		Platform:  "linux/arm64",
+	})
+	if err != nil {
+		t.Fatalf("FetchManifest() error = %v", err)
+	}
+	if got := digest.FromBytes(output.Raw()); got != arm64.Digest {
+		t.Fatalf("FetchManifest() fetched %s, want %s", got, arm64.Digest)
+	}
+	if result == nil {
+		t.Fatal("Expected result to report the selected descriptor")
+	}
+	if got := result.Meta["descriptor"].(ocispec.Descriptor); got.Digest != arm64.Digest {
+		t.Errorf("descriptor = %v, want %v", got, arm64)
+	}
+	if got := result.Content[1].(*mcp.TextContent).Text; !strings.Contains(got, index.Digest.String()) {
+		t.Errorf("descriptor content = %s, want it to contain the index digest %s", got, index.Digest)
+	}
+
+	// image manifests are checked against the platform of the config
+	_, output, err = FetchManifest(ctx, nil, InputFetchManifest{
+		Reference: host + "/test-repo:single",
+		Platform:  "linux/amd64",
+	})
+	if err != nil {
+		t.Fatalf("FetchManifest() error = %v", err)
+	}
+	if got := digest.FromBytes(output.Raw()); got != amd64.Digest {
+		t.Fatalf("FetchManifest() fetched %s, want %s", got, amd64.Digest)
+	}
+
+	for _, tc := range []struct {
+		reference string
+		platform  string
+		errorMsg  string
+	}{
+		{"multi", "linux/s390x", "available platforms: linux/amd64, linux/arm64/v8"},
+		{"single", "linux/arm64", "instead of linux/arm64"},
+		{"multi", "linux", "invalid platform"},
+	} {
+		_, _, err := FetchManifest(ctx, nil, InputFetchManifest{
+			Reference: host + "/test-repo:" + tc.reference,
+			Platform:  tc.platform,
+		})
+		if err == nil || !strings.Contains(err.Error(), tc.errorMsg) {
+			t.Errorf("FetchManifest(%s, %s) error = %v, want error containing %q", tc.reference, tc.platform, err, tc.errorMsg)
+		}
+	}
+}
+
 func TestFetchManifest_InvalidInput(t *testing.T) {
 	testCases := []struct {
 		name  string
@@ -203,6 +358,25 @@ func TestFetchManifest_InvalidInput(t *testing.T) {
 				Tag:        "latest",
 			},
 		},
+		{
+			name: "invalid reference string",
+			input: InputFetchManifest{
+				Reference: "localhost:5000/INVALID_REPO:latest",
+			},
+		},
+		{
+			name: "reference without tag or digest",
+			input: InputFetchManifest{
+				Reference: "localhost:5000/repo",
+			},
+		},
+		{
+			name: "reference conflicts with components",
+			input: InputFetchManifest{
+				Reference: "localhost:5000/repo:latest",
+				Tag:       "v1",
+			},
+		},
 	}
 
 	ctx := context.Background()
@@ -0,0 +1,94 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Limits of the number of items listed per page.
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// errPageFull stops the paging once the page is filled.
+var errPageFull = errors.New("page full")
+
+// pageCursor is the continuation state carried by the opaque cursor.
+type pageCursor struct {
+	Last string `json:"last"`
+}
+
+// encodeCursor encodes the cursor continuing the listing after last.
+func encodeCursor(last string) string {
+	// json.Marshal on pageCursor never fails; safe to ignore the error.
+	cursorJSON, _ := json.Marshal(pageCursor{Last: last})
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+// pageStart returns the item to list after and the number of items to list,
+// given the last, limit and cursor inputs.
+func pageStart(last string, limit int, cursor string) (string, int, error) {
+	switch {
+	case limit == 0:
+		limit = defaultPageLimit
+	case limit < 0 || limit > maxPageLimit:
+		return "", 0, fmt.Errorf("limit must be between 1 and %d, got %d", maxPageLimit, limit)
+	}
+	if cursor == "" {
+		return last, limit, nil
+	}
+	if last != "" {
+		return "", 0, errors.New("last and cursor are mutually exclusive")
+	}
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c pageCursor
+	if err := json.Unmarshal(cursorJSON, &c); err != nil {
+		return "", 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return c.Last, limit, nil
+}
+
+// listPage lists at most limit items after last with the paginated list
+// function, stopping the paging once the page is filled. The returned cursor
+// continues the listing, and is empty if there are no more items.
+func listPage(ctx context.Context, last string, limit int, list listFunc) ([]string, string, error) {
+	items := []string{}
+	err := list(ctx, last, func(page []string) error {
+		items = append(items, page...)
+		// one more item than the limit tells if there are more items
+		if len(items) > limit {
+			return errPageFull
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errPageFull) {
+		return nil, "", err
+	}
+	if len(items) <= limit {
+		return items, "", nil
+	}
+	items = items[:limit]
+	return items, encodeCursor(items[limit-1]), nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPageStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		last      string
+		limit     int
+		cursor    string
+		wantLast  string
+		wantLimit int
+		wantErr   bool
+	}{
+		{
+			name:      "defaults",
+			wantLimit: defaultPageLimit,
+		},
+		{
+			name:      "last and limit",
+			last:      "v1",
+			limit:     10,
+			wantLast:  "v1",
+			wantLimit: 10,
+		},
+		{
+			name:      "cursor",
+			limit:     maxPageLimit,
+			cursor:    encodeCursor("v2"),
+			wantLast:  "v2",
+			wantLimit: maxPageLimit,
+		},
+		{
+			name:    "negative limit",
+			limit:   -1,
+			wantErr: true,
+		},
+		{
+			name:    "limit too large",
+			limit:   maxPageLimit + 1,
+			wantErr: true,
+		},
+		{
+			name:    "last and cursor",
+			last:    "v1",
+			cursor:  encodeCursor("v2"),
+			wantErr: true,
+		},
+		{
+			name:    "malformed cursor",
+			cursor:  "not a cursor!",
+			wantErr: true,
+		},
+		{
+			name:    "non-JSON cursor",
+			cursor:  "bm90IGpzb24",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last, limit, err := pageStart(tt.last, tt.limit, tt.cursor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pageStart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if last != tt.wantLast || limit != tt.wantLimit {
+				t.Errorf("pageStart() = %q, %d, want %q, %d", last, limit, tt.wantLast, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestListPage(t *testing.T) {
+	pages := [][]string{{"a", "b"}, {"c", "d"}, {"e"}}
+	list := func(ctx context.Context, last string, fn func(items []string) error) error {
+		for _, page := range pages {
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		want     []string
+		wantNext string
+	}{
+		{
+			name:     "stop within a page",
+			limit:    3,
+			want:     []string{"a", "b", "c"},
+			wantNext: "c",
+		},
+		{
+			name:     "stop at the end of a page",
+			limit:    2,
+			want:     []string{"a", "b"},
+			wantNext: "b",
+		},
+		{
+			name:  "list all items",
+			limit: 5,
+			want:  []string{"a", "b", "c", "d", "e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next, err := listPage(context.Background(), "", tt.limit, list)
+			if err != nil {
+				t.Fatalf("listPage() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listPage() = %v, want %v", got, tt.want)
+			}
+			wantNext := ""
+			if tt.wantNext != "" {
+				wantNext = encodeCursor(tt.wantNext)
+			}
+			if next != wantNext {
+				t.Errorf("listPage() next = %q, want %q", next, wantNext)
+			}
+		})
+	}
+
+	t.Run("list error", func(t *testing.T) {
+		wantErr := errors.New("list failed")
+		_, _, err := listPage(context.Background(), "", 10, func(context.Context, string, func([]string) error) error {
+			return wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("listPage() error = %v, want %v", err, wantErr)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// Annotations of the attestation manifests in the indexes built by BuildKit.
+const (
+	annotationReferenceType   = "vnd.docker.reference.type"
+	annotationReferenceDigest = "vnd.docker.reference.digest"
+)
+
+// MetadataListPlatforms describes the ListPlatforms tool.
+var MetadataListPlatforms = &mcp.Tool{
+	Name:        "list_platforms",
+	Description: "List platforms of a multi-platform container image, with attestation manifests listed separately.",
+}
+
+// InputListPlatforms is the input for the ListPlatforms tool.
+type InputListPlatforms struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
+}
+
+// OutputListPlatforms is the output for the ListPlatforms tool.
+type OutputListPlatforms struct {
+	MediaType    string             `json:"mediaType" jsonschema:"media type of the manifest"`
+	Digest       string             `json:"digest" jsonschema:"manifest digest"`
+	Platforms    []PlatformManifest `json:"platforms" jsonschema:"manifests of the platforms, or the manifest itself if it is a single-platform image"`
+	Attestations []PlatformManifest `json:"attestations,omitempty" jsonschema:"attestation manifests, such as provenance and SBOMs attached by BuildKit"`
+}
+
+// PlatformManifest is a manifest of a platform in an index.
+type PlatformManifest struct {
+	Platform    string            `json:"platform,omitempty" jsonschema:"platform in the format of os/arch[/variant][:os_version], absent if unknown"`
+	MediaType   string            `json:"mediaType" jsonschema:"media type of the manifest"`
+	Digest      string            `json:"digest" jsonschema:"manifest digest"`
+	Size        int64             `json:"size" jsonschema:"manifest size in bytes"`
+	Annotations map[string]string `json:"annotations,omitempty" jsonschema:"annotations of the descriptor"`
+	Subject     string            `json:"subject,omitempty" jsonschema:"digest of the platform manifest attested, reported for attestations"`
+}
+
+// ListPlatforms lists platforms of a multi-platform container image.
+func ListPlatforms(ctx context.Context, _ *mcp.CallToolRequest, input InputListPlatforms) (*mcp.CallToolResult, OutputListPlatforms, error) {
+	// validate input
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputListPlatforms{}, err
+	}
+	if ref.Reference == "" {
+		return nil, OutputListPlatforms{}, fmt.Errorf("either tag or digest is required")
+	}
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
+
+	// fetch the manifest
+	desc, manifestBytes, err := fetchManifest(ctx, repo, ref.Reference)
+	if err != nil {
+		return nil, OutputListPlatforms{}, err
+	}
+	output := OutputListPlatforms{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest.String(),
+		Platforms: []PlatformManifest{},
+	}
+	if !isIndex(desc.MediaType) {
+		// a single-platform image is of the platform of its config
+		if desc.Platform, err = fetchImagePlatform(ctx, repo, desc, manifestBytes); err != nil {
+			return nil, OutputListPlatforms{}, err
+		}
+		output.Platforms = append(output.Platforms, platformManifest(desc))
+		result, err := repositoryResult(repo, original, output)
+		return result, output, err
+	}
+
+	var index ocispec.Index
+	if err := json.Unmarshal(manifestBytes, &index); err != nil {
+		return nil, OutputListPlatforms{}, fmt.Errorf("failed to parse index %s: %w", desc.Digest, err)
+	}
+	for _, child := range index.Manifests {
+		manifest := platformManifest(child)
+		if isAttestation(child) {
+			manifest.Subject = child.Annotations[annotationReferenceDigest]
+			output.Attestations = append(output.Attestations, manifest)
+		} else {
+			output.Platforms = append(output.Platforms, manifest)
+		}
+	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
+
+// platformManifest returns the platform manifest described by desc.
+func platformManifest(desc ocispec.Descriptor) PlatformManifest {
+	return PlatformManifest{
+		Platform:    formatPlatform(desc.Platform),
+		MediaType:   desc.MediaType,
+		Digest:      desc.Digest.String(),
+		Size:        desc.Size,
+		Annotations: desc.Annotations,
+	}
+}
+
+// isAttestation reports whether the manifest described by desc in an index is
+// an attestation manifest instead of an image of a real platform.
+func isAttestation(desc ocispec.Descriptor) bool {
+	if desc.Annotations[annotationReferenceType] == "attestation-manifest" {
+		return true
+	}
+	return desc.Platform != nil && desc.Platform.OS == "unknown" && desc.Platform.Architecture == "unknown"
+}
+
+// parsePlatform parses the platform in the format of
+// os/arch[/variant][:os_version].
+func parsePlatform(s string) (*ocispec.Platform, error) {
+	var platform ocispec.Platform
+	name, osVersion, _ := strings.Cut(s, ":")
+	platform.OSVersion = osVersion
+	parts := strings.Split(name, "/")
+	switch len(parts) {
+	case 3:
+		platform.Variant = parts[2]
+		fallthrough
+	case 2:
+		platform.OS = parts[0]
+		platform.Architecture = parts[1]
+	default:
+		return nil, fmt.Errorf("invalid platform %q: expected format os/arch[/variant][:os_version]", s)
+	}
+	if platform.OS == "" || platform.Architecture == "" || (len(parts) == 3 && platform.Variant == "") {
+		return nil, fmt.Errorf("invalid platform %q: expected format os/arch[/variant][:os_version]", s)
+	}
+	return &platform, nil
+}
+
+// formatPlatform formats the platform in the format of
+// os/arch[/variant][:os_version].
+func formatPlatform(platform *ocispec.Platform) string {
+	if platform == nil {
+		return ""
+	}
+	s := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		s += "/" + platform.Variant
+	}
+	if platform.OSVersion != "" {
+		s += ":" + platform.OSVersion
+	}
+	return s
+}
+
+// matchPlatform reports whether got satisfies want, where the variant and the
+// OS version of want match any if unset.
+func matchPlatform(got, want *ocispec.Platform) bool {
+	if got == nil {
+		return false
+	}
+	return got.OS == want.OS &&
+		got.Architecture == want.Architecture &&
+		(want.Variant == "" || got.Variant == want.Variant) &&
+		(want.OSVersion == "" || got.OSVersion == want.OSVersion)
+}
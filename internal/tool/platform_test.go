@@ -0,0 +1,186 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestListPlatforms(t *testing.T) {
+	reg := newTestRegistry()
+	amd64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}})
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := reg.pushImage(ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}})
+	arm64.Platform = &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	attestation := reg.pushImage(ocispec.Image{})
+	attestation.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+	attestation.Annotations = map[string]string{
+		"vnd.docker.reference.digest": amd64.Digest.String(),
+		"vnd.docker.reference.type":   "attestation-manifest",
+	}
+	index := reg.pushIndex("multi", amd64, arm64, attestation)
+	reg.manifests["single"] = arm64
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	ctx := context.Background()
+	_, output, err := ListPlatforms(ctx, nil, InputListPlatforms{Reference: host + "/test-repo:multi"})
+	if err != nil {
+		t.Fatalf("ListPlatforms() error = %v", err)
+	}
+	want := OutputListPlatforms{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    index.Digest.String(),
+		Platforms: []PlatformManifest{
+			{Platform: "linux/amd64", MediaType: ocispec.MediaTypeImageManifest, Digest: amd64.Digest.String(), Size: amd64.Size},
+			{Platform: "linux/arm64/v8", MediaType: ocispec.MediaTypeImageManifest, Digest: arm64.Digest.String(), Size: arm64.Size},
+		},
+		Attestations: []PlatformManifest{
+			{
+				Platform:    "unknown/unknown",
+				MediaType:   ocispec.MediaTypeImageManifest,
+				Digest:      attestation.Digest.String(),
+				Size:        attestation.Size,
+				Annotations: attestation.Annotations,
+				Subject:     amd64.Digest.String(),
+			},
+		},
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("ListPlatforms() = %+v, want %+v", output, want)
+	}
+
+	// a single-platform image is listed as the only platform
+	_, output, err = ListPlatforms(ctx, nil, InputListPlatforms{Reference: host + "/test-repo:single"})
+	if err != nil {
+		t.Fatalf("ListPlatforms() error = %v", err)
+	}
+	want = OutputListPlatforms{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    arm64.Digest.String(),
+		Platforms: []PlatformManifest{
+			{Platform: "linux/arm64/v8", MediaType: ocispec.MediaTypeImageManifest, Digest: arm64.Digest.String(), Size: arm64.Size},
+		},
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("ListPlatforms() = %+v, want %+v", output, want)
+	}
+}
+
+func TestListPlatforms_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    InputListPlatforms
+		errorMsg string
+	}{
+		{
+			name:     "empty input",
+			input:    InputListPlatforms{},
+			errorMsg: "required",
+		},
+		{
+			name:     "reference without tag",
+			input:    InputListPlatforms{Reference: "localhost:5000/test-repo"},
+			errorMsg: "either tag or digest is required",
+		},
+		{
+			name:     "both forms",
+			input:    InputListPlatforms{Reference: "localhost:5000/test-repo:v1", Tag: "v2"},
+			errorMsg: "reference conflicts",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ListPlatforms(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ListPlatforms() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		platform string
+		want     ocispec.Platform
+		wantErr  bool
+	}{
+		{
+			platform: "linux/amd64",
+			want:     ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			platform: "linux/arm64/v8",
+			want:     ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+		{
+			platform: "windows/amd64:10.0.17763.5820",
+			want:     ocispec.Platform{OS: "windows", Architecture: "amd64", OSVersion: "10.0.17763.5820"},
+		},
+		{platform: "linux", wantErr: true},
+		{platform: "linux/", wantErr: true},
+		{platform: "linux/arm/", wantErr: true},
+		{platform: "linux/arm/v7/extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			got, err := parsePlatform(tt.platform)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePlatform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.OS != tt.want.OS || got.Architecture != tt.want.Architecture || got.Variant != tt.want.Variant || got.OSVersion != tt.want.OSVersion {
+				t.Errorf("parsePlatform() = %+v, want %+v", got, tt.want)
+			}
+			if s := formatPlatform(got); s != tt.platform {
+				t.Errorf("formatPlatform() = %s, want %s", s, tt.platform)
+			}
+		})
+	}
+}
+
+func TestMatchPlatform(t *testing.T) {
+	arm64 := &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	tests := []struct {
+		name string
+		got  *ocispec.Platform
+		want *ocispec.Platform
+		ok   bool
+	}{
+		{"exact", arm64, &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}, true},
+		{"any variant", arm64, &ocispec.Platform{OS: "linux", Architecture: "arm64"}, true},
+		{"other variant", arm64, &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v9"}, false},
+		{"other architecture", arm64, &ocispec.Platform{OS: "linux", Architecture: "amd64"}, false},
+		{"other os version", arm64, &ocispec.Platform{OS: "linux", Architecture: "arm64", OSVersion: "1"}, false},
+		{"no platform", nil, &ocispec.Platform{OS: "linux", Architecture: "arm64"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchPlatform(tt.got, tt.want); got != tt.ok {
+				t.Errorf("matchPlatform() = %v, want %v", got, tt.ok)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package tool
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"oras.land/oras-go/v2/registry"
@@ -32,6 +34,7 @@ var MetadataParseReference = &mcp.Tool{
 // InputParseReference is the input for the ParseReference tool.
 type InputParseReference struct {
 	Reference string `json:"reference" jsonschema:"reference string"`
+	Normalize bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization, such as nginx to docker.io/library/nginx:latest"`
 }
 
 // OutputParseReference is the output for the ParseReference tool.
@@ -40,6 +43,8 @@ type OutputParseReference struct {
 	Repository string `json:"repository" jsonschema:"repository name"`
 	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
 	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Original   string `json:"original,omitempty" jsonschema:"given reference string, reported if normalizing"`
+	Normalized string `json:"normalized,omitempty" jsonschema:"normalized reference string, reported if normalizing"`
 }
 
 // ParseReference parses a reference string into its components.
@@ -49,7 +54,11 @@ func ParseReference(ctx context.Context, _ *mcp.CallToolRequest, input InputPars
 	}
 
 	// parse the reference
-	ref, err := registry.ParseReference(input.Reference)
+	reference := input.Reference
+	if input.Normalize {
+		reference = normalizeReference(reference)
+	}
+	ref, err := registry.ParseReference(reference)
 	if err != nil {
 		return nil, OutputParseReference{}, fmt.Errorf("invalid reference string format: %w", err)
 	}
@@ -58,6 +67,10 @@ func ParseReference(ctx context.Context, _ *mcp.CallToolRequest, input InputPars
 		Registry:   ref.Registry,
 		Repository: ref.Repository,
 	}
+	if input.Normalize {
+		output.Original = input.Reference
+		output.Normalized = ref.String()
+	}
 
 	// set tag and digest if present
 	if ref.Reference != "" {
@@ -70,3 +83,115 @@ func ParseReference(ctx context.Context, _ *mcp.CallToolRequest, input InputPars
 
 	return nil, output, nil
 }
+
+// artifactReference returns the validated reference of an artifact given
+// either as a reference string or as its components. The digest takes
+// precedence over the tag. If normalize is set, Docker-style normalization is
+// applied and the given form is returned as well if it is changed.
+func artifactReference(reference, registryName, repository, tag, digest string, normalize bool) (registry.Reference, string, error) {
+	if reference != "" {
+		var conflicts []string
+		for _, component := range []struct{ name, value string }{
+			{"registry", registryName},
+			{"repository", repository},
+			{"tag", tag},
+			{"digest", digest},
+		} {
+			if component.value != "" {
+				conflicts = append(conflicts, component.name)
+			}
+		}
+		if len(conflicts) > 0 {
+			return registry.Reference{}, "", fmt.Errorf("reference conflicts with %s: specify either the reference string or its components", strings.Join(conflicts, ", "))
+		}
+		if !normalize {
+			ref, err := registry.ParseReference(reference)
+			if err != nil {
+				return registry.Reference{}, "", fmt.Errorf("invalid reference string format: %w", err)
+			}
+			return ref, "", nil
+		}
+		ref, err := registry.ParseReference(normalizeReference(reference))
+		if err != nil {
+			return registry.Reference{}, "", fmt.Errorf("invalid reference string format: %w", err)
+		}
+		if ref.String() == reference {
+			return ref, "", nil
+		}
+		return ref, reference, nil
+	}
+
+	given := registry.Reference{
+		Registry:   registryName,
+		Repository: repository,
+		Reference:  tag,
+	}
+	if digest != "" {
+		given.Reference = digest
+	}
+	ref := given
+	if normalize {
+		ref.Registry, ref.Repository = normalizeRepository(ref.Registry, ref.Repository)
+		if ref.Reference == "" {
+			ref.Reference = defaultTag
+		}
+	}
+	if ref.Registry == "" || ref.Repository == "" {
+		return registry.Reference{}, "", fmt.Errorf("either reference or registry and repository names are required")
+	}
+	if err := ref.Validate(); err != nil {
+		return registry.Reference{}, "", err
+	}
+	if ref == given {
+		return ref, "", nil
+	}
+	// the registry of the given components may be empty
+	return ref, strings.TrimPrefix(given.String(), "/"), nil
+}
+
+// Docker-style defaults applied by normalization.
+const (
+	dockerRegistry  = "docker.io"
+	dockerNamespace = "library"
+	defaultTag      = "latest"
+)
+
+// dockerHosts are the hosts of Docker Hub named as docker.io.
+var dockerHosts = []string{"index.docker.io", "registry-1.docker.io"}
+
+// normalizeReference applies Docker-style normalization to the reference
+// string: the registry defaults to docker.io, official images of Docker Hub
+// are placed under library/, and the tag defaults to latest.
+func normalizeReference(reference string) string {
+	name, suffix := reference, ""
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, suffix = name[:i], name[i:]
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, suffix = name[:i], name[i:]+suffix
+	}
+	if suffix == "" {
+		suffix = ":" + defaultTag
+	}
+
+	// like Docker, the first path component is the registry only if it looks
+	// like a host name
+	registryName, repository := "", name
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registryName, repository = first, rest
+	}
+	registryName, repository = normalizeRepository(registryName, repository)
+	return registryName + "/" + repository + suffix
+}
+
+// normalizeRepository applies Docker-style normalization to the registry and
+// repository names.
+func normalizeRepository(registryName, repository string) (string, string) {
+	if registryName == "" || slices.Contains(dockerHosts, registryName) {
+		registryName = dockerRegistry
+	}
+	if registryName == dockerRegistry && repository != "" && !strings.Contains(repository, "/") {
+		repository = dockerNamespace + "/" + repository
+	}
+	return registryName, repository
+}
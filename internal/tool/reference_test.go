@@ -127,3 +127,148 @@ func TestParseReference_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestParseReference_Normalize(t *testing.T) {
+	const validDigest = "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+	tests := []struct {
+		reference string
+		want      OutputParseReference
+	}{
+		{
+			reference: "nginx",
+			want: OutputParseReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "latest",
+				Normalized: "docker.io/library/nginx:latest",
+			},
+		},
+		{
+			reference: "library/nginx:1.25",
+			want: OutputParseReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "1.25",
+				Normalized: "docker.io/library/nginx:1.25",
+			},
+		},
+		{
+			reference: "bitnami/redis@" + validDigest,
+			want: OutputParseReference{
+				Registry:   "docker.io",
+				Repository: "bitnami/redis",
+				Digest:     validDigest,
+				Normalized: "docker.io/bitnami/redis@" + validDigest,
+			},
+		},
+		{
+			reference: "index.docker.io/nginx",
+			want: OutputParseReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "latest",
+				Normalized: "docker.io/library/nginx:latest",
+			},
+		},
+		{
+			reference: "localhost:5000/hello-world",
+			want: OutputParseReference{
+				Registry:   "localhost:5000",
+				Repository: "hello-world",
+				Tag:        "latest",
+				Normalized: "localhost:5000/hello-world:latest",
+			},
+		},
+		{
+			reference: "localhost/hello-world:v1",
+			want: OutputParseReference{
+				Registry:   "localhost",
+				Repository: "hello-world",
+				Tag:        "v1",
+				Normalized: "localhost/hello-world:v1",
+			},
+		},
+		{
+			reference: "registry.example.com/nginx:v1",
+			want: OutputParseReference{
+				Registry:   "registry.example.com",
+				Repository: "nginx",
+				Tag:        "v1",
+				Normalized: "registry.example.com/nginx:v1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			_, output, err := ParseReference(context.Background(), nil, InputParseReference{Reference: tt.reference, Normalize: true})
+			if err != nil {
+				t.Fatalf("ParseReference() unexpected error: %v", err)
+			}
+			tt.want.Original = tt.reference
+			if output != tt.want {
+				t.Fatalf("ParseReference() output = %+v, want %+v", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestArtifactReference_Normalize(t *testing.T) {
+	tests := []struct {
+		name         string
+		reference    string
+		registryName string
+		repository   string
+		tag          string
+		want         string
+		wantOriginal string
+	}{
+		{
+			name:         "reference string",
+			reference:    "nginx:1.25",
+			want:         "docker.io/library/nginx:1.25",
+			wantOriginal: "nginx:1.25",
+		},
+		{
+			name:      "normalized reference string",
+			reference: "docker.io/library/nginx:1.25",
+			want:      "docker.io/library/nginx:1.25",
+		},
+		{
+			name:         "implicit registry",
+			repository:   "nginx",
+			want:         "docker.io/library/nginx:latest",
+			wantOriginal: "nginx",
+		},
+		{
+			name:         "docker hub host",
+			registryName: "registry-1.docker.io",
+			repository:   "library/nginx",
+			tag:          "1.25",
+			want:         "docker.io/library/nginx:1.25",
+			wantOriginal: "registry-1.docker.io/library/nginx:1.25",
+		},
+		{
+			name:         "other registry",
+			registryName: "registry.example.com",
+			repository:   "nginx",
+			tag:          "v1",
+			want:         "registry.example.com/nginx:v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, original, err := artifactReference(tt.reference, tt.registryName, tt.repository, tt.tag, "", true)
+			if err != nil {
+				t.Fatalf("artifactReference() error = %v", err)
+			}
+			if got := ref.String(); got != tt.want {
+				t.Errorf("artifactReference() = %s, want %s", got, tt.want)
+			}
+			if original != tt.wantOriginal {
+				t.Errorf("artifactReference() original = %q, want %q", original, tt.wantOriginal)
+			}
+		})
+	}
+}
@@ -42,11 +42,14 @@ var MetadataListReferrers = &mcp.Tool{
 
 // InputListReferrers is the input for the ListReferrers tool.
 type InputListReferrers struct {
-	Registry     string `json:"registry" jsonschema:"registry name"`
-	Repository   string `json:"repository" jsonschema:"repository name"`
+	Reference    string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry     string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository   string `json:"repository,omitempty" jsonschema:"repository name"`
 	Tag          string `json:"tag,omitempty" jsonschema:"tag name"`
 	Digest       string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize    bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
 	ArtifactType string `json:"artifactType,omitempty" jsonschema:"filter by artifact type"`
+	Refresh      bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
 }
 
 // OutputListReferrers is the output for the ListReferrers tool.
@@ -79,27 +82,18 @@ type ListReferrersNode struct {
 // ListReferrers lists referrers of a container image or an OCI artifact.
 func ListReferrers(ctx context.Context, _ *mcp.CallToolRequest, input InputListReferrers) (*mcp.CallToolResult, OutputListReferrers, error) {
 	// validate input
-	if input.Registry == "" || input.Repository == "" {
-		return nil, OutputListReferrers{}, fmt.Errorf("registry and repository names are required")
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputListReferrers{}, err
 	}
-	if input.Tag == "" && input.Digest == "" {
+	if ref.Reference == "" {
 		return nil, OutputListReferrers{}, fmt.Errorf("either tag or digest is required")
 	}
-	ref := registry.Reference{
-		Registry:   input.Registry,
-		Repository: input.Repository,
-		Reference:  input.Tag,
-	}
-	if input.Digest != "" {
-		ref.Reference = input.Digest
-	}
-	if err := ref.Validate(); err != nil {
-		return nil, OutputListReferrers{}, err
-	}
-	repo := remote.NewRepository(ref)
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
 
 	// resolve the reference to get the descriptor
-	desc, err := repo.Manifests().Resolve(ctx, ref.Reference)
+	desc, err := repo.Resolve(ctx, ref.Reference)
 	if err != nil {
 		return nil, OutputListReferrers{}, err
 	}
@@ -119,7 +113,8 @@ func ListReferrers(ctx context.Context, _ *mcp.CallToolRequest, input InputListR
 	output := OutputListReferrers{
 		tree: json.RawMessage(rootJSON),
 	}
-	return nil, output, nil
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
 }
 
 // fetchAllReferrers fetches all referrers of the root node recursively.
@@ -121,6 +121,19 @@ func TestListReferrers_InvalidInput(t *testing.T) {
 				Digest:     "invalid-digest",
 			},
 		},
+		{
+			name: "reference without tag or digest",
+			input: InputListReferrers{
+				Reference: "localhost:5000/repo",
+			},
+		},
+		{
+			name: "reference conflicts with components",
+			input: InputListReferrers{
+				Reference:  "localhost:5000/repo:latest",
+				Repository: "repo",
+			},
+		},
 	}
 
 	ctx := context.Background()
@@ -17,6 +17,7 @@ package tool
 
 import (
 	"context"
+	"slices"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -45,15 +46,8 @@ type OutputListWellknownRegistries struct {
 
 // ListWellknownRegistries lists well-known public registries with catalog support.
 func ListWellknownRegistries(ctx context.Context, _ *mcp.CallToolRequest, _ InputListWellknownRegistries) (*mcp.CallToolResult, OutputListWellknownRegistries, error) {
-	registries := []Registry{
-		{
-			Name:        "mcr.microsoft.com",
-			Description: "Microsoft Container Registry",
-		},
-	}
-
 	output := OutputListWellknownRegistries{
-		Registries: registries,
+		Registries: slices.Clone(wellknownRegistries),
 	}
 	return nil, output, nil
 }
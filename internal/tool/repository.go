@@ -17,11 +17,13 @@ package tool
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/oras-project/oras-mcp/internal/remote"
-	"oras.land/oras-go/v2/registry"
 )
 
 // MetadataListRepositories describes the ListRepositories tool.
@@ -33,32 +35,155 @@ var MetadataListRepositories = &mcp.Tool{
 // InputListRepositories is the input for the ListRepositories tool.
 type InputListRepositories struct {
 	Registry string `json:"registry" jsonschema:"registry name"`
+	Last     string `json:"last,omitempty" jsonschema:"list the repositories after this repository"`
+	Limit    int    `json:"limit,omitempty" jsonschema:"maximum number of repositories to list, 100 by default and at most 1000"`
+	Cursor   string `json:"cursor,omitempty" jsonschema:"cursor returned as next by the previous call to continue the listing"`
+	Prefix   string `json:"prefix,omitempty" jsonschema:"list the repositories starting with this prefix, such as a namespace followed by a slash"`
+	Include  string `json:"include,omitempty" jsonschema:"glob pattern, or regular expression enclosed in slashes, of the repositories to list"`
+	Exclude  string `json:"exclude,omitempty" jsonschema:"glob pattern, or regular expression enclosed in slashes, of the repositories not to list"`
+	Group    bool   `json:"group,omitempty" jsonschema:"group the repositories by the next namespace level below the prefix with counts instead of listing them, to drill down by calling again with a namespace as the prefix"`
 }
 
 // OutputListRepositories is the output for the ListRepositories tool.
 type OutputListRepositories struct {
-	Repositories []string `json:"repositories" jsonschema:"list of repositories"`
+	Repositories []string              `json:"repositories" jsonschema:"list of repositories"`
+	Namespaces   []RepositoryNamespace `json:"namespaces,omitempty" jsonschema:"namespaces below the prefix, listed if grouping"`
+	Next         string                `json:"next,omitempty" jsonschema:"cursor to list the next repositories, absent if all repositories are listed"`
 }
 
+// RepositoryNamespace is a namespace of repositories.
+type RepositoryNamespace struct {
+	Name         string `json:"name" jsonschema:"namespace name"`
+	Count        int    `json:"count" jsonschema:"number of repositories in the namespace, including the namespace itself if it is a repository"`
+	IsRepository bool   `json:"isRepository,omitempty" jsonschema:"whether the namespace itself is a repository"`
+}
+
+// errListDone stops the listing once no more items can match.
+var errListDone = errors.New("list done")
+
 // ListRepositories lists repositories of a container registry.
 func ListRepositories(ctx context.Context, _ *mcp.CallToolRequest, input InputListRepositories) (*mcp.CallToolResult, OutputListRepositories, error) {
 	// validate input
 	if input.Registry == "" {
 		return nil, OutputListRepositories{}, fmt.Errorf("registry name is required")
 	}
-	reg, err := remote.NewRegistry(input.Registry)
+	last, limit, err := pageStart(input.Last, input.Limit, input.Cursor)
+	if err != nil {
+		return nil, OutputListRepositories{}, err
+	}
+	reg, err := remote.NewRegistry(ctx, input.Registry)
 	if err != nil {
 		return nil, OutputListRepositories{}, err
 	}
+	filter, err := newNameFilter(input.Include, input.Exclude)
+	if err != nil {
+		return nil, OutputListRepositories{}, err
+	}
+	list := filter.filterList(listUnderPrefix(reg.Repositories, input.Prefix))
 
 	// list repositories
-	repositories, err := registry.Repositories(ctx, reg)
+	if input.Group {
+		reg.RepositoryListPageSize = maxPageLimit
+		namespaces, next, err := groupNamespaces(ctx, list, input.Prefix, last, limit)
+		if err != nil {
+			return nil, OutputListRepositories{}, err
+		}
+		output := OutputListRepositories{
+			Repositories: []string{},
+			Namespaces:   namespaces,
+			Next:         next,
+		}
+		return nil, output, nil
+	}
+	reg.RepositoryListPageSize = limit + 1
+	repositories, next, err := listPage(ctx, last, limit, list)
 	if err != nil {
 		return nil, OutputListRepositories{}, err
 	}
 
 	output := OutputListRepositories{
 		Repositories: repositories,
+		Next:         next,
 	}
 	return nil, output, nil
 }
+
+// listUnderPrefix returns the list function listing the items of list starting
+// with prefix. As catalogs are listed in lexical order, the listing starts
+// right before the prefix and stops once past it.
+func listUnderPrefix(list listFunc, prefix string) listFunc {
+	if prefix == "" {
+		return list
+	}
+	return func(ctx context.Context, last string, fn func(items []string) error) error {
+		// any item starting with prefix comes after its proper prefix
+		if start := prefix[:len(prefix)-1]; last < start {
+			last = start
+		}
+		err := list(ctx, last, func(items []string) error {
+			var matched []string
+			done := false
+			for _, item := range items {
+				if strings.HasPrefix(item, prefix) {
+					matched = append(matched, item)
+				} else if item > prefix {
+					done = true
+					break
+				}
+			}
+			if len(matched) > 0 {
+				if err := fn(matched); err != nil {
+					return err
+				}
+			}
+			if done {
+				return errListDone
+			}
+			return nil
+		})
+		if errors.Is(err, errListDone) {
+			return nil
+		}
+		return err
+	}
+}
+
+// groupNamespaces lists all items of list and groups them by the next
+// namespace level below prefix, returning at most limit namespaces after the
+// namespace last.
+func groupNamespaces(ctx context.Context, list listFunc, prefix, last string, limit int) ([]RepositoryNamespace, string, error) {
+	groups := make(map[string]*RepositoryNamespace)
+	if err := list(ctx, "", func(items []string) error {
+		for _, item := range items {
+			segment, _, nested := strings.Cut(strings.TrimPrefix(item, prefix), "/")
+			name := prefix + segment
+			group, ok := groups[name]
+			if !ok {
+				group = &RepositoryNamespace{Name: name}
+				groups[name] = group
+			}
+			group.Count++
+			if !nested {
+				group.IsRepository = true
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, "", err
+	}
+
+	namespaces := make([]RepositoryNamespace, 0, len(groups))
+	for _, group := range groups {
+		if group.Name > last {
+			namespaces = append(namespaces, *group)
+		}
+	}
+	slices.SortFunc(namespaces, func(a, b RepositoryNamespace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	if len(namespaces) <= limit {
+		return namespaces, "", nil
+	}
+	namespaces = namespaces[:limit]
+	return namespaces, encodeCursor(namespaces[limit-1].Name), nil
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"slices"
+	"sync/atomic"
 	"testing"
 )
 
@@ -63,6 +64,150 @@ func TestListRepositoriesSuccess(t *testing.T) {
 	}
 }
 
+func TestListRepositoriesPagination(t *testing.T) {
+	repositories := []string{"repo1", "repo2", "repo3", "repo4"}
+	var requests atomic.Int64
+	ts := httptest.NewServer(paginatedHandler("/v2/_catalog", "repositories", repositories, &requests))
+	defer ts.Close()
+
+	registry := getLocalhostServerURL(ts.URL)
+	_, output, err := ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Limit: 3})
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if want := []string{"repo1", "repo2", "repo3"}; !slices.Equal(output.Repositories, want) {
+		t.Fatalf("Repositories = %v, want %v", output.Repositories, want)
+	}
+	if output.Next == "" {
+		t.Fatal("expected next cursor")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+
+	_, output, err = ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Limit: 3, Cursor: output.Next})
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if want := []string{"repo4"}; !slices.Equal(output.Repositories, want) || output.Next != "" {
+		t.Fatalf("Repositories = %v, next %q, want %v without next", output.Repositories, output.Next, want)
+	}
+
+	if _, _, err := ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Limit: maxPageLimit + 1}); err == nil {
+		t.Fatal("expected error for limit too large, got nil")
+	}
+}
+
+func TestListRepositoriesPrefixAndFilter(t *testing.T) {
+	repositories := []string{"apps/api", "apps/web", "team", "team-x/app", "team/a", "team/b/c", "team/b/d", "tools/cli"}
+	var requests atomic.Int64
+	ts := httptest.NewServer(paginatedHandler("/v2/_catalog", "repositories", repositories, &requests))
+	defer ts.Close()
+	registry := getLocalhostServerURL(ts.URL)
+
+	tests := []struct {
+		name  string
+		input InputListRepositories
+		want  []string
+	}{
+		{
+			name:  "prefix",
+			input: InputListRepositories{Prefix: "team/"},
+			want:  []string{"team/a", "team/b/c", "team/b/d"},
+		},
+		{
+			name:  "prefix and glob",
+			input: InputListRepositories{Prefix: "team/", Include: "team/b/*"},
+			want:  []string{"team/b/c", "team/b/d"},
+		},
+		{
+			name:  "exclude regular expression",
+			input: InputListRepositories{Exclude: "/^team/"},
+			want:  []string{"apps/api", "apps/web", "tools/cli"},
+		},
+		{
+			name:  "no match",
+			input: InputListRepositories{Prefix: "none/"},
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			input.Registry = registry
+			_, output, err := ListRepositories(context.Background(), nil, input)
+			if err != nil {
+				t.Fatalf("ListRepositories() error = %v", err)
+			}
+			if !slices.Equal(output.Repositories, tt.want) || output.Repositories == nil {
+				t.Errorf("Repositories = %v, want %v", output.Repositories, tt.want)
+			}
+		})
+	}
+
+	t.Run("paging stops past the prefix", func(t *testing.T) {
+		requests.Store(0)
+		_, output, err := ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Prefix: "apps/", Limit: 1})
+		if err != nil {
+			t.Fatalf("ListRepositories() error = %v", err)
+		}
+		_, output, err = ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Prefix: "apps/", Limit: 1, Cursor: output.Next})
+		if err != nil {
+			t.Fatalf("ListRepositories() error = %v", err)
+		}
+		if want := []string{"apps/web"}; !slices.Equal(output.Repositories, want) || output.Next != "" {
+			t.Errorf("Repositories = %v, next %q, want %v without next", output.Repositories, output.Next, want)
+		}
+		if got := requests.Load(); got != 2 {
+			t.Errorf("requests = %d, want 2", got)
+		}
+	})
+}
+
+func TestListRepositoriesGroup(t *testing.T) {
+	repositories := []string{"apps/api", "apps/web", "team", "team-x/app", "team/a", "team/b/c", "team/b/d", "tools/cli"}
+	ts := httptest.NewServer(paginatedHandler("/v2/_catalog", "repositories", repositories, nil))
+	defer ts.Close()
+	registry := getLocalhostServerURL(ts.URL)
+
+	_, output, err := ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Group: true, Limit: 3})
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	want := []RepositoryNamespace{
+		{Name: "apps", Count: 2},
+		{Name: "team", Count: 4, IsRepository: true},
+		{Name: "team-x", Count: 1},
+	}
+	if !slices.Equal(output.Namespaces, want) {
+		t.Errorf("Namespaces = %v, want %v", output.Namespaces, want)
+	}
+	if len(output.Repositories) != 0 || output.Next == "" {
+		t.Fatalf("Repositories = %v, next %q, want no repositories with next", output.Repositories, output.Next)
+	}
+
+	_, output, err = ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Group: true, Limit: 3, Cursor: output.Next})
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if want := []RepositoryNamespace{{Name: "tools", Count: 1}}; !slices.Equal(output.Namespaces, want) || output.Next != "" {
+		t.Errorf("Namespaces = %v, next %q, want %v without next", output.Namespaces, output.Next, want)
+	}
+
+	// drill down
+	_, output, err = ListRepositories(context.Background(), nil, InputListRepositories{Registry: registry, Group: true, Prefix: "team/"})
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	want = []RepositoryNamespace{
+		{Name: "team/a", Count: 1, IsRepository: true},
+		{Name: "team/b", Count: 2},
+	}
+	if !slices.Equal(output.Namespaces, want) {
+		t.Errorf("Namespaces = %v, want %v", output.Namespaces, want)
+	}
+}
+
 func TestListRepositoriesMissingRegistry(t *testing.T) {
 	if _, _, err := ListRepositories(context.Background(), nil, InputListRepositories{}); err == nil {
 		t.Fatal("expected error for missing registry, got nil")
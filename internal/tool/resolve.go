@@ -0,0 +1,80 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// MetadataResolveReference describes the ResolveReference tool.
+var MetadataResolveReference = &mcp.Tool{
+	Name:        "resolve_reference",
+	Description: "Resolve a tag or digest of a container image or an OCI artifact to its descriptor without fetching the manifest.",
+}
+
+// InputResolveReference is the input for the ResolveReference tool.
+type InputResolveReference struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string, as an alternative to registry, repository, tag, and digest"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Tag        string `json:"tag,omitempty" jsonschema:"tag name"`
+	Digest     string `json:"digest,omitempty" jsonschema:"manifest digest"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx:latest"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"resolve the tag against the registry instead of the tag cache"`
+}
+
+// OutputResolveReference is the output for the ResolveReference tool.
+type OutputResolveReference struct {
+	MediaType    string            `json:"mediaType" jsonschema:"media type of the manifest"`
+	Digest       string            `json:"digest" jsonschema:"manifest digest"`
+	Size         int64             `json:"size" jsonschema:"manifest size in bytes"`
+	ArtifactType string            `json:"artifactType,omitempty" jsonschema:"artifact type, if known"`
+	Annotations  map[string]string `json:"annotations,omitempty" jsonschema:"annotations of the descriptor, if known"`
+}
+
+// ResolveReference resolves a tag or digest to the descriptor of the manifest.
+func ResolveReference(ctx context.Context, _ *mcp.CallToolRequest, input InputResolveReference) (*mcp.CallToolResult, OutputResolveReference, error) {
+	// validate input
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, input.Tag, input.Digest, input.Normalize)
+	if err != nil {
+		return nil, OutputResolveReference{}, err
+	}
+	if ref.Reference == "" {
+		return nil, OutputResolveReference{}, fmt.Errorf("either tag or digest is required")
+	}
+	repo := remote.NewRepository(ctx, ref)
+	repo.Refresh = input.Refresh
+
+	// resolve the reference with a HEAD request
+	desc, err := repo.Resolve(ctx, ref.Reference)
+	if err != nil {
+		return nil, OutputResolveReference{}, err
+	}
+
+	output := OutputResolveReference{
+		MediaType:    desc.MediaType,
+		Digest:       desc.Digest.String(),
+		Size:         desc.Size,
+		ArtifactType: desc.ArtifactType,
+		Annotations:  desc.Annotations,
+	}
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
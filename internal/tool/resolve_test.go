@@ -0,0 +1,139 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestResolveReference(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	dgst := digest.FromBytes(manifest)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected method accessed: %s", r.Method)
+		}
+		if r.URL.Path != "/v2/test-repo/manifests/v1" && r.URL.Path != "/v2/test-repo/manifests/"+dgst.String() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+	}))
+	defer ts.Close()
+	registry := getLocalhostServerURL(ts.URL)
+
+	want := OutputResolveReference{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    dgst.String(),
+		Size:      int64(len(manifest)),
+	}
+	tests := []struct {
+		name  string
+		input InputResolveReference
+	}{
+		{
+			name:  "components with tag",
+			input: InputResolveReference{Registry: registry, Repository: "test-repo", Tag: "v1", Refresh: true},
+		},
+		{
+			name:  "components with digest",
+			input: InputResolveReference{Registry: registry, Repository: "test-repo", Digest: dgst.String()},
+		},
+		{
+			name:  "reference string",
+			input: InputResolveReference{Reference: registry + "/test-repo:v1", Refresh: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, output, err := ResolveReference(context.Background(), nil, tt.input)
+			if err != nil {
+				t.Fatalf("ResolveReference() error = %v", err)
+			}
+			if result != nil {
+				t.Errorf("expected MCP result to be nil, got %v", result)
+			}
+			if output.MediaType != want.MediaType || output.Digest != want.Digest || output.Size != want.Size {
+				t.Errorf("ResolveReference() = %+v, want %+v", output, want)
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		if _, _, err := ResolveReference(context.Background(), nil, InputResolveReference{Reference: registry + "/test-repo:missing"}); err == nil {
+			t.Fatal("expected error for missing tag, got nil")
+		}
+	})
+}
+
+func TestResolveReference_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    InputResolveReference
+		errorMsg string
+	}{
+		{
+			name:     "empty input",
+			input:    InputResolveReference{},
+			errorMsg: "required",
+		},
+		{
+			name:     "missing tag and digest",
+			input:    InputResolveReference{Registry: "localhost:5000", Repository: "test-repo"},
+			errorMsg: "either tag or digest is required",
+		},
+		{
+			name:     "reference without tag",
+			input:    InputResolveReference{Reference: "localhost:5000/test-repo"},
+			errorMsg: "either tag or digest is required",
+		},
+		{
+			name:     "invalid reference string",
+			input:    InputResolveReference{Reference: "localhost:5000/INVALID:v1"},
+			errorMsg: "invalid reference string format",
+		},
+		{
+			name:     "both forms",
+			input:    InputResolveReference{Reference: "localhost:5000/test-repo:v1", Tag: "v2"},
+			errorMsg: "reference conflicts",
+		},
+		{
+			name:     "invalid tag",
+			input:    InputResolveReference{Registry: "localhost:5000", Repository: "test-repo", Tag: "-invalid"},
+			errorMsg: "invalid reference",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ResolveReference(context.Background(), nil, tt.input)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+// resultNote is a note on how a request was served, carried by the tool result
+// as metadata under key and as text.
+type resultNote struct {
+	key   string
+	value any
+	text  string
+}
+
+// repositoryResult returns the tool result carrying the output together with
+// the notes on how the request was served, or nil if there is nothing to note.
+// The reference is noted if it is normalized from original, followed by the
+// given notes, and the endpoint that served the content of repo if it is not
+// the requested repository.
+func repositoryResult(repo *remote.Repository, original string, output any, notes ...resultNote) (*mcp.CallToolResult, error) {
+	reference, endpoint := repositoryNotes(repo, original, "")
+	return noteResult(output, slices.Concat(reference, notes, endpoint))
+}
+
+// repositoryNotes returns the note on the reference of repo if it is
+// normalized from original, and the note on the endpoint that served the
+// content of repo if it is not the requested repository. The keys of the notes
+// are prefixed by prefix, if any, to tell the repositories of a request apart.
+func repositoryNotes(repo *remote.Repository, original, prefix string) (reference, endpoint []resultNote) {
+	if original != "" {
+		reference = append(reference, resultNote{
+			key:   noteKey(prefix, "reference"),
+			value: repo.Reference.String(),
+			text:  fmt.Sprintf("Normalized %s to %s.", original, repo.Reference),
+		})
+	}
+	if repo.Mirrored() {
+		served := repo.Endpoint()
+		name := served.Registry + "/" + served.Repository
+		endpoint = append(endpoint, resultNote{
+			key:   noteKey(prefix, "endpoint"),
+			value: name,
+			text:  fmt.Sprintf("Served by %s instead of %s/%s.", name, repo.Reference.Registry, repo.Reference.Repository),
+		})
+	}
+	return reference, endpoint
+}
+
+// noteKey returns the key of a note prefixed in camel case, such as
+// fromReference for the reference key prefixed by from.
+func noteKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + strings.ToUpper(key[:1]) + key[1:]
+}
+
+// noteResult returns the tool result carrying the output together with the
+// notes, or nil if there are no notes.
+func noteResult(output any, notes []resultNote) (*mcp.CallToolResult, error) {
+	if len(notes) == 0 {
+		return nil, nil
+	}
+
+	// the SDK only generates the text content of the output if the content is
+	// unset, so it is generated here as well
+	outputJSON, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	result := &mcp.CallToolResult{
+		Meta: mcp.Meta{},
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(outputJSON)},
+		},
+	}
+	for _, note := range notes {
+		result.Meta[note.key] = note.value
+		result.Content = append(result.Content, &mcp.TextContent{Text: note.text})
+	}
+	return result, nil
+}
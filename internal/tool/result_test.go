@@ -0,0 +1,138 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/oras-project/oras-mcp/internal/config"
+	"github.com/oras-project/oras-mcp/internal/remote"
+)
+
+func TestRepositoryResult_Endpoint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/mirror/test-repo/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"mirror/test-repo","tags":["v1.0"]}`))
+	}))
+	defer ts.Close()
+	mirror := getLocalhostServerURL(ts.URL)
+
+	t.Cleanup(func() {
+		remote.Configure(config.Default().Registry)
+	})
+	cfg := config.Default().Registry
+	cfg.Mirrors = []config.RegistryMirror{
+		{
+			Prefix:  "registry.example",
+			Mirrors: []string{mirror + "/mirror"},
+		},
+	}
+	remote.Configure(cfg)
+
+	result, output, err := ListTags(context.Background(), nil, InputListTags{
+		Registry:   "registry.example",
+		Repository: "test-repo",
+	})
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if len(output.Tags) != 1 || output.Tags[0] != "v1.0" {
+		t.Errorf("ListTags() = %v, want [v1.0]", output.Tags)
+	}
+	if result == nil {
+		t.Fatal("Expected result to report the endpoint")
+	}
+	endpoint := mirror + "/mirror/test-repo"
+	if got := result.Meta["endpoint"]; got != endpoint {
+		t.Errorf("endpoint = %v, want %s", got, endpoint)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected 2 content blocks, got %d", len(result.Content))
+	}
+	if got := result.Content[0].(*mcp.TextContent).Text; got != `{"tags":["v1.0"]}` {
+		t.Errorf("output content = %s, want %s", got, `{"tags":["v1.0"]}`)
+	}
+	if got := result.Content[1].(*mcp.TextContent).Text; !strings.Contains(got, endpoint) {
+		t.Errorf("endpoint content = %s, want it to contain %s", got, endpoint)
+	}
+}
+
+func TestRepositoryResult_Normalized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/test-repo/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"test-repo","tags":["v1.0"]}`))
+	}))
+	defer ts.Close()
+	host := getLocalhostServerURL(ts.URL)
+
+	result, _, err := ListTags(context.Background(), nil, InputListTags{
+		Registry:   host,
+		Repository: "test-repo",
+		Normalize:  true,
+	})
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if result != nil {
+		t.Fatalf("Expected no result for an unchanged reference, got %v", result)
+	}
+
+	// the mirror rewrites docker.io to the test server
+	t.Cleanup(func() {
+		remote.Configure(config.Default().Registry)
+	})
+	cfg := config.Default().Registry
+	cfg.Mirrors = []config.RegistryMirror{
+		{
+			Prefix:   "docker.io/library/test-repo",
+			Location: host + "/test-repo",
+		},
+	}
+	remote.Configure(cfg)
+
+	result, _, err = ListTags(context.Background(), nil, InputListTags{
+		Reference: "test-repo",
+		Normalize: true,
+	})
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result to report the normalized reference")
+	}
+	if got := result.Meta["reference"]; got != "docker.io/library/test-repo" {
+		t.Errorf("reference = %v, want docker.io/library/test-repo", got)
+	}
+	if len(result.Content) != 3 {
+		t.Fatalf("Expected 3 content blocks, got %d", len(result.Content))
+	}
+	if got, want := result.Content[1].(*mcp.TextContent).Text, "Normalized test-repo to docker.io/library/test-repo."; got != want {
+		t.Errorf("reference content = %s, want %s", got, want)
+	}
+}
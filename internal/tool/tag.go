@@ -17,10 +17,20 @@ package tool
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/oras-project/oras-mcp/internal/remote"
-	"oras.land/oras-go/v2/registry"
+	"golang.org/x/mod/semver"
+)
+
+// Sort modes of the ListTags tool.
+const (
+	sortLexical       = "lexical"
+	sortSemver        = "semver"
+	sortSemverRelease = "semver_release"
 )
 
 // MetadataListTags describes the ListTags tool.
@@ -31,35 +41,129 @@ var MetadataListTags = &mcp.Tool{
 
 // InputListTags is the input for the ListTags tool.
 type InputListTags struct {
-	Registry   string `json:"registry" jsonschema:"registry name"`
-	Repository string `json:"repository" jsonschema:"repository name"`
+	Reference  string `json:"reference,omitempty" jsonschema:"full reference string of the repository, as an alternative to registry and repository, ignoring any tag or digest in it"`
+	Registry   string `json:"registry,omitempty" jsonschema:"registry name"`
+	Repository string `json:"repository,omitempty" jsonschema:"repository name"`
+	Normalize  bool   `json:"normalize,omitempty" jsonschema:"apply Docker-style normalization to the reference, such as nginx to docker.io/library/nginx"`
+	Last       string `json:"last,omitempty" jsonschema:"list the tags after this tag"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"maximum number of tags to list, 100 by default and at most 1000"`
+	Cursor     string `json:"cursor,omitempty" jsonschema:"cursor returned as next by the previous call to continue the listing"`
+	Include    string `json:"include,omitempty" jsonschema:"glob pattern, or regular expression enclosed in slashes like /^v1\\./, of the tags to list"`
+	Exclude    string `json:"exclude,omitempty" jsonschema:"glob pattern, or regular expression enclosed in slashes, of the tags not to list"`
+	Sort       string `json:"sort,omitempty" jsonschema:"sort mode, options: lexical (default), semver (semantic versions in descending order, skipping other tags), semver_release (same as semver, skipping pre-releases)"`
 }
 
 // OutputListTags is the output for the ListTags tool.
 type OutputListTags struct {
-	Tags []string `json:"tags" jsonschema:"list of tags"`
+	Tags         []string `json:"tags" jsonschema:"list of tags"`
+	Next         string   `json:"next,omitempty" jsonschema:"cursor to list the next tags, absent if all tags are listed"`
+	LatestSemver string   `json:"latest_semver,omitempty" jsonschema:"highest release version among the listed tags, reported by the semver sort modes"`
 }
 
 // ListTags lists tags in a repository of a container registry.
 func ListTags(ctx context.Context, _ *mcp.CallToolRequest, input InputListTags) (*mcp.CallToolResult, OutputListTags, error) {
 	// validate input
-	ref := registry.Reference{
-		Registry:   input.Registry,
-		Repository: input.Repository,
+	ref, original, err := artifactReference(input.Reference, input.Registry, input.Repository, "", "", input.Normalize)
+	if err != nil {
+		return nil, OutputListTags{}, err
+	}
+	// any tag or digest of the reference string is ignored
+	ref.Reference = ""
+	if original == ref.String() {
+		original = ""
+	}
+	last, limit, err := pageStart(input.Last, input.Limit, input.Cursor)
+	if err != nil {
+		return nil, OutputListTags{}, err
 	}
-	if err := ref.Validate(); err != nil {
+	filter, err := newNameFilter(input.Include, input.Exclude)
+	if err != nil {
 		return nil, OutputListTags{}, err
 	}
-	repo := remote.NewRepository(ref)
+	repo := remote.NewRepository(ctx, ref)
 
 	// list tags
-	tags, err := registry.Tags(ctx, repo)
+	var output OutputListTags
+	switch input.Sort {
+	case "", sortLexical:
+		repo.TagListPageSize = limit + 1
+		output.Tags, output.Next, err = listPage(ctx, last, limit, filter.filterList(repo.Tags))
+	case sortSemver, sortSemverRelease:
+		// sorting by versions requires all tags
+		repo.TagListPageSize = maxPageLimit
+		output, err = listSemverTags(ctx, filter.filterList(repo.Tags), input.Sort == sortSemverRelease, last, limit)
+	default:
+		return nil, OutputListTags{}, fmt.Errorf("unsupported sort mode %q, options: %s, %s, %s", input.Sort, sortLexical, sortSemver, sortSemverRelease)
+	}
 	if err != nil {
 		return nil, OutputListTags{}, err
 	}
 
-	output := OutputListTags{
-		Tags: tags,
+	result, err := repositoryResult(repo, original, output)
+	return result, output, err
+}
+
+// listSemverTags lists at most limit tags which are semantic versions, in
+// descending order of versions after the tag last.
+func listSemverTags(ctx context.Context, list listFunc, releaseOnly bool, last string, limit int) (OutputListTags, error) {
+	if last != "" && semverOf(last) == "" {
+		return OutputListTags{}, fmt.Errorf("last tag %q is not a semantic version", last)
+	}
+	tags := []string{}
+	if err := list(ctx, "", func(page []string) error {
+		for _, tag := range page {
+			version := semverOf(tag)
+			if version == "" || (releaseOnly && semver.Prerelease(version) != "") {
+				continue
+			}
+			tags = append(tags, tag)
+		}
+		return nil
+	}); err != nil {
+		return OutputListTags{}, err
+	}
+	slices.SortFunc(tags, compareSemverDesc)
+
+	var output OutputListTags
+	for _, tag := range tags {
+		if semver.Prerelease(semverOf(tag)) == "" {
+			output.LatestSemver = tag
+			break
+		}
+	}
+	if last != "" {
+		start, _ := slices.BinarySearchFunc(tags, last, compareSemverDesc)
+		if start < len(tags) && tags[start] == last {
+			start++
+		}
+		tags = tags[start:]
+	}
+	if len(tags) > limit {
+		tags = tags[:limit]
+		output.Next = encodeCursor(tags[limit-1])
+	}
+	output.Tags = tags
+	return output, nil
+}
+
+// semverOf returns the semantic version of the tag with or without the v
+// prefix, or an empty string if the tag is not a semantic version.
+func semverOf(tag string) string {
+	version := tag
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return ""
+	}
+	return version
+}
+
+// compareSemverDesc compares the tags in descending order of their semantic
+// versions, breaking ties by the tags themselves.
+func compareSemverDesc(a, b string) int {
+	if c := semver.Compare(semverOf(b), semverOf(a)); c != 0 {
+		return c
 	}
-	return nil, output, nil
+	return strings.Compare(a, b)
 }
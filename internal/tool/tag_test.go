@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sync/atomic"
 	"testing"
 )
 
@@ -66,6 +67,166 @@ func TestListTags_ValidInput(t *testing.T) {
 	}
 }
 
+func TestListTags_Reference(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/test-repo/tags/list" {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"test-repo","tags":["v1.0","v1.1"]}`))
+	}))
+	defer ts.Close()
+
+	// the tag of the reference is ignored
+	input := InputListTags{
+		Reference: getLocalhostServerURL(ts.URL) + "/test-repo:v1.0",
+	}
+	_, output, err := ListTags(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if want := []string{"v1.0", "v1.1"}; !reflect.DeepEqual(output.Tags, want) {
+		t.Errorf("ListTags() = %v, want %v", output.Tags, want)
+	}
+}
+
+func TestListTags_Pagination(t *testing.T) {
+	tags := []string{"v1.0", "v1.1", "v1.2", "v2.0", "v2.1"}
+	var requests atomic.Int64
+	ts := httptest.NewServer(paginatedHandler("/v2/test-repo/tags/list", "tags", tags, &requests))
+	defer ts.Close()
+
+	ctx := context.Background()
+	input := InputListTags{
+		Registry:   getLocalhostServerURL(ts.URL),
+		Repository: "test-repo",
+		Limit:      2,
+	}
+	var got []string
+	for pages := 1; ; pages++ {
+		_, output, err := ListTags(ctx, nil, input)
+		if err != nil {
+			t.Fatalf("ListTags() error = %v", err)
+		}
+		if len(output.Tags) > 2 {
+			t.Fatalf("ListTags() = %v, want at most 2 tags", output.Tags)
+		}
+		got = append(got, output.Tags...)
+		if output.Next == "" {
+			if pages != 3 {
+				t.Errorf("pages = %d, want 3", pages)
+			}
+			break
+		}
+		input.Cursor = output.Next
+	}
+	if !reflect.DeepEqual(got, tags) {
+		t.Errorf("ListTags() = %v, want %v", got, tags)
+	}
+	// each page is served by a single request of limit+1 tags
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+
+	_, output, err := ListTags(ctx, nil, InputListTags{
+		Registry:   input.Registry,
+		Repository: "test-repo",
+		Last:       "v1.2",
+	})
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if want := []string{"v2.0", "v2.1"}; !reflect.DeepEqual(output.Tags, want) || output.Next != "" {
+		t.Errorf("ListTags() = %v, %q, want %v, no next", output.Tags, output.Next, want)
+	}
+}
+
+func TestListTags_FilterAndSort(t *testing.T) {
+	tags := []string{"1.9.0", "latest", "v1.10.0", "v1.10.1-rc.1", "v1.2.0", "v1.2.0-alpine", "v2.0.0-beta.1"}
+	ts := httptest.NewServer(paginatedHandler("/v2/test-repo/tags/list", "tags", tags, nil))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		input      InputListTags
+		want       []string
+		wantLatest string
+	}{
+		{
+			name:  "lexical with filters",
+			input: InputListTags{Include: "v1.*", Exclude: "/-(rc|alpine)/"},
+			want:  []string{"v1.10.0", "v1.2.0"},
+		},
+		{
+			name:       "semver descending",
+			input:      InputListTags{Sort: sortSemver},
+			want:       []string{"v2.0.0-beta.1", "v1.10.1-rc.1", "v1.10.0", "1.9.0", "v1.2.0", "v1.2.0-alpine"},
+			wantLatest: "v1.10.0",
+		},
+		{
+			name:       "semver releases",
+			input:      InputListTags{Sort: sortSemverRelease},
+			want:       []string{"v1.10.0", "1.9.0", "v1.2.0"},
+			wantLatest: "v1.10.0",
+		},
+		{
+			name:       "semver releases with filter",
+			input:      InputListTags{Sort: sortSemverRelease, Include: "/^v?1\\.[0-9]\\./"},
+			want:       []string{"1.9.0", "v1.2.0"},
+			wantLatest: "1.9.0",
+		},
+		{
+			name:       "semver after last",
+			input:      InputListTags{Sort: sortSemver, Last: "1.9.0"},
+			want:       []string{"v1.2.0", "v1.2.0-alpine"},
+			wantLatest: "v1.10.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			input.Registry = getLocalhostServerURL(ts.URL)
+			input.Repository = "test-repo"
+			_, output, err := ListTags(context.Background(), nil, input)
+			if err != nil {
+				t.Fatalf("ListTags() error = %v", err)
+			}
+			if !reflect.DeepEqual(output.Tags, tt.want) {
+				t.Errorf("ListTags() = %v, want %v", output.Tags, tt.want)
+			}
+			if output.LatestSemver != tt.wantLatest {
+				t.Errorf("LatestSemver = %q, want %q", output.LatestSemver, tt.wantLatest)
+			}
+		})
+	}
+
+	t.Run("semver pagination", func(t *testing.T) {
+		input := InputListTags{
+			Registry:   getLocalhostServerURL(ts.URL),
+			Repository: "test-repo",
+			Sort:       sortSemver,
+			Limit:      4,
+		}
+		_, output, err := ListTags(context.Background(), nil, input)
+		if err != nil {
+			t.Fatalf("ListTags() error = %v", err)
+		}
+		if output.Next == "" {
+			t.Fatal("expected next cursor")
+		}
+		input.Cursor = output.Next
+		_, output, err = ListTags(context.Background(), nil, input)
+		if err != nil {
+			t.Fatalf("ListTags() error = %v", err)
+		}
+		if want := []string{"v1.2.0", "v1.2.0-alpine"}; !reflect.DeepEqual(output.Tags, want) || output.Next != "" {
+			t.Errorf("ListTags() = %v, next %q, want %v without next", output.Tags, output.Next, want)
+		}
+	})
+}
+
 func TestListTags_InvalidInput(t *testing.T) {
 	// Test cases for invalid inputs
 	testCases := []struct {
@@ -101,6 +262,15 @@ func TestListTags_InvalidInput(t *testing.T) {
 			wantErr:  true,
 			errorMsg: "invalid registry",
 		},
+		{
+			name: "reference conflicts with components",
+			input: InputListTags{
+				Reference:  "localhost:5000/test-repo",
+				Repository: "test-repo",
+			},
+			wantErr:  true,
+			errorMsg: "reference conflicts with repository",
+		},
 		{
 			name: "invalid repository",
 			input: InputListTags{
@@ -110,6 +280,58 @@ func TestListTags_InvalidInput(t *testing.T) {
 			wantErr:  true,
 			errorMsg: "invalid repository",
 		},
+		{
+			name: "invalid limit",
+			input: InputListTags{
+				Registry:   "localhost:5000",
+				Repository: "test-repo",
+				Limit:      -1,
+			},
+			wantErr:  true,
+			errorMsg: "invalid limit",
+		},
+		{
+			name: "last and cursor",
+			input: InputListTags{
+				Registry:   "localhost:5000",
+				Repository: "test-repo",
+				Last:       "v1",
+				Cursor:     encodeCursor("v2"),
+			},
+			wantErr:  true,
+			errorMsg: "last and cursor",
+		},
+		{
+			name: "invalid include pattern",
+			input: InputListTags{
+				Registry:   "localhost:5000",
+				Repository: "test-repo",
+				Include:    "/(/",
+			},
+			wantErr:  true,
+			errorMsg: "invalid include pattern",
+		},
+		{
+			name: "unsupported sort mode",
+			input: InputListTags{
+				Registry:   "localhost:5000",
+				Repository: "test-repo",
+				Sort:       "random",
+			},
+			wantErr:  true,
+			errorMsg: "unsupported sort mode",
+		},
+		{
+			name: "non-semver last tag",
+			input: InputListTags{
+				Registry:   "localhost:5000",
+				Repository: "test-repo",
+				Sort:       sortSemver,
+				Last:       "latest",
+			},
+			wantErr:  true,
+			errorMsg: "not a semantic version",
+		},
 	}
 
 	for _, tt := range testCases {
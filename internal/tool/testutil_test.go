@@ -16,8 +16,24 @@ limitations under the License.
 package tool
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
 	"net"
+	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // getLocalhostServerURL extracts the port from a test server URL and returns a localhost URL.
@@ -32,3 +48,197 @@ func getLocalhostServerURL(serverURL string) string {
 	}
 	return "localhost:" + port
 }
+
+// paginatedHandler serves the sorted items at path as a paginated listing in
+// the JSON field named key, honoring the n and last parameters and linking the
+// next page. The number of requests served is counted by requests if not nil.
+func paginatedHandler(path, key string, items []string, requests *atomic.Int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if requests != nil {
+			requests.Add(1)
+		}
+		query := r.URL.Query()
+		start := 0
+		if last := query.Get("last"); last != "" {
+			start, _ = slices.BinarySearch(items, last)
+			if start < len(items) && items[start] == last {
+				start++
+			}
+		}
+		end := len(items)
+		if n, err := strconv.Atoi(query.Get("n")); err == nil && start+n < end {
+			end = start + n
+			w.Header().Set("Link", fmt.Sprintf(`<%s?n=%d&last=%s>; rel="next"`, path, n, url.QueryEscape(items[end-1])))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string][]string{key: items[start:end]})
+	}
+}
+
+// testRegistry is an in-memory registry serving the repository test-repo.
+type testRegistry struct {
+	manifests map[string]ocispec.Descriptor
+	content   map[digest.Digest][]byte
+}
+
+// newTestRegistry returns an empty test registry.
+func newTestRegistry() *testRegistry {
+	return &testRegistry{
+		manifests: make(map[string]ocispec.Descriptor),
+		content:   make(map[digest.Digest][]byte),
+	}
+}
+
+// pushManifest stores the manifest marshalled in JSON, tagged if tag is not
+// empty, and returns its descriptor.
+func (r *testRegistry) pushManifest(mediaType string, manifest any, tag string) ocispec.Descriptor {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		panic("failed to marshal manifest: " + err.Error())
+	}
+	desc := r.pushBlob(mediaType, data)
+	r.manifests[desc.Digest.String()] = desc
+	if tag != "" {
+		r.manifests[tag] = desc
+	}
+	return desc
+}
+
+// pushBlob stores the blob and returns its descriptor.
+func (r *testRegistry) pushBlob(mediaType string, data []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	r.content[desc.Digest] = data
+	return desc
+}
+
+// ServeHTTP serves the manifests and the blobs of test-repo.
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var desc ocispec.Descriptor
+	var ok bool
+	if reference, found := strings.CutPrefix(req.URL.Path, "/v2/test-repo/manifests/"); found {
+		desc, ok = r.manifests[reference]
+	} else if dgst, found := strings.CutPrefix(req.URL.Path, "/v2/test-repo/blobs/"); found {
+		desc.Digest = digest.Digest(dgst)
+		desc.MediaType = "application/octet-stream"
+		_, ok = r.content[desc.Digest]
+	}
+	if !ok || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", desc.MediaType)
+	w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(r.content[desc.Digest]))
+}
+
+// pushImage stores the image config and the manifest of an image with the
+// layers, and returns the descriptor of the manifest.
+func (r *testRegistry) pushImage(config ocispec.Image, layers ...ocispec.Descriptor) ocispec.Descriptor {
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		panic("failed to marshal config: " + err.Error())
+	}
+	if layers == nil {
+		layers = []ocispec.Descriptor{}
+	}
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    r.pushBlob(ocispec.MediaTypeImageConfig, configBytes),
+		Layers:    layers,
+	}
+	return r.pushManifest(ocispec.MediaTypeImageManifest, manifest, "")
+}
+
+// pushIndex stores the index of the manifests, tagged if tag is not empty, and
+// returns its descriptor.
+func (r *testRegistry) pushIndex(tag string, manifests ...ocispec.Descriptor) ocispec.Descriptor {
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	}
+	return r.pushManifest(ocispec.MediaTypeImageIndex, index, tag)
+}
+
+// configSize returns the size of the config of the stored image manifest.
+func (r *testRegistry) configSize(desc ocispec.Descriptor) int64 {
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(r.content[desc.Digest], &manifest); err != nil {
+		panic("failed to unmarshal manifest: " + err.Error())
+	}
+	return manifest.Config.Size
+}
+
+// testEntry is an entry of a test layer. Directories are named with a trailing
+// slash, and the entry is a symlink if link is set, or a hardlink if hardlink
+// is set.
+type testEntry struct {
+	name     string
+	content  string
+	link     string
+	hardlink string
+}
+
+// buildLayer returns the tar archive of the entries.
+func buildLayer(entries ...testEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(entry.content)),
+		}
+		switch {
+		case strings.HasSuffix(entry.name, "/"):
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		case entry.link != "":
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = entry.link
+			hdr.Mode = 0777
+		case entry.hardlink != "":
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = entry.hardlink
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			panic("failed to write tar header: " + err.Error())
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			panic("failed to write tar content: " + err.Error())
+		}
+	}
+	if err := tw.Close(); err != nil {
+		panic("failed to close tar writer: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+// gzipLayer returns the layer compressed by gzip.
+func gzipLayer(layer []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(layer)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// zstdLayer returns the layer compressed by zstd.
+func zstdLayer(layer []byte) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		panic("failed to create zstd encoder: " + err.Error())
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(layer, nil)
+}